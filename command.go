@@ -0,0 +1,132 @@
+package logwrap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// CommandStreamField is the name of the field containing the stream, stdout or stderr, a line of output was read from.
+const CommandStreamField = "stream"
+
+// CommandPIDField is the name of the field containing the process ID of the command.
+const CommandPIDField = "pid"
+
+// CommandExitCodeField is the name of the field containing the exit code of the command.
+const CommandExitCodeField = "exitCode"
+
+// CommandDurationField is the name of the field containing the duration the command ran for.
+const CommandDurationField = "duration"
+
+const commandArgsField = "args"
+
+// Command is a wrapper around an exec.Cmd which logs its output and exit status, it should be constructed with
+// Logger.Command.
+type Command struct {
+	cmd     *exec.Cmd
+	logger  Logger
+	ctx     context.Context
+	options []Option
+	stdout  io.WriteCloser
+	stderr  io.WriteCloser
+	started time.Time
+}
+
+// Command attaches the logger to an exec.Cmd which has not yet been started, each line written to stdout or stderr is
+// logged as a message at the levels provided. If the command already has a stdout or stderr they continue to receive
+// output.
+//
+// Messages have their Source set to the name of the executable, which may be overridden with options, and include the
+// CommandStreamField and CommandPIDField fields. As messages are logged with the context provided, they are part of any
+// Segment within it. Once the command has exited a message is logged with its CommandExitCodeField and
+// CommandDurationField, at Info if successful or Error otherwise.
+//
+// The command must be started with the Start or Run methods of the returned Command, rather than those of the exec.Cmd.
+func (l Logger) Command(ctx context.Context, cmd *exec.Cmd, stdoutLevel LogLevel, stderrLevel LogLevel, options ...Option) *Command {
+	c := &Command{
+		cmd:     cmd,
+		logger:  l,
+		ctx:     ctx,
+		options: append([]Option{Source(filepath.Base(cmd.Path))}, options...),
+	}
+
+	c.options = append(c.options, func(message *Message) {
+		if cmd.Process != nil {
+			message.Data[CommandPIDField] = cmd.Process.Pid
+		}
+	})
+
+	c.stdout = l.Writer(ctx, stdoutLevel, c.withOptions(Datum(CommandStreamField, "stdout"))...)
+	c.stderr = l.Writer(ctx, stderrLevel, c.withOptions(Datum(CommandStreamField, "stderr"))...)
+
+	cmd.Stdout = attachWriter(cmd.Stdout, c.stdout)
+	cmd.Stderr = attachWriter(cmd.Stderr, c.stderr)
+
+	return c
+}
+
+// Start starts the command, logging its arguments at Debug.
+func (c *Command) Start() error {
+	c.started = time.Now()
+
+	if err := c.cmd.Start(); err != nil {
+		c.logger.Error(c.ctx, "process failed to start", c.withOptions(Datum(commandArgsField, c.cmd.Args), Err(err))...)
+		return err
+	}
+
+	c.logger.Debug(c.ctx, "process started", c.withOptions(Datum(commandArgsField, c.cmd.Args))...)
+	return nil
+}
+
+// Wait waits for the command to exit, logging any remaining output followed by its exit code and duration.
+func (c *Command) Wait() error {
+	err := c.cmd.Wait()
+	duration := time.Since(c.started)
+
+	_ = c.stdout.Close()
+	_ = c.stderr.Close()
+
+	options := c.withOptions(Datum(CommandDurationField, duration))
+
+	if c.cmd.ProcessState != nil {
+		options = append(options, Datum(CommandExitCodeField, c.cmd.ProcessState.ExitCode()))
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			options = append(options, Err(err))
+		}
+
+		c.logger.Error(c.ctx, "process exited", options...)
+	} else {
+		c.logger.Info(c.ctx, "process exited", options...)
+	}
+
+	return err
+}
+
+// Run starts the command and waits for it to exit.
+func (c *Command) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+// withOptions returns a copy of the commands options with the additional options appended.
+func (c *Command) withOptions(options ...Option) []Option {
+	return append(append([]Option{}, c.options...), options...)
+}
+
+func attachWriter(existing io.Writer, writer io.Writer) io.Writer {
+	if existing == nil {
+		return writer
+	}
+
+	return io.MultiWriter(existing, writer)
+}
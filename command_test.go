@@ -0,0 +1,100 @@
+package logwrap
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestLogger_Command(t *testing.T) {
+	t.Run("logs each line of stdout and stderr with the stream, pid and source, followed by the exit status", func(t *testing.T) {
+		if _, err := exec.LookPath("sh"); err != nil {
+			t.Skip("sh not available")
+		}
+
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Times(4)
+
+		var existingStdout bytes.Buffer
+
+		logger := New(mockImpl.Impl)
+		cmd := exec.Command("sh", "-c", "echo out; echo err 1>&2")
+		cmd.Stdout = &existingStdout
+
+		assert.NoError(t, logger.Command(context.Background(), cmd, Info, Warn).Run())
+		assert.True(t, mockImpl.AssertExpectations(t))
+
+		messages := map[string]Message{}
+		for _, call := range mockImpl.Calls {
+			message := call.Arguments.Get(1).(Message)
+			messages[message.Message] = message
+		}
+
+		assert.Equal(t, "out\n", existingStdout.String())
+
+		started := messages["process started"]
+		assert.Equal(t, Debug, started.Level)
+		assert.Equal(t, "sh", started.Source)
+		assert.Equal(t, cmd.Process.Pid, started.Data[CommandPIDField])
+
+		stdout := messages["out"]
+		assert.Equal(t, Info, stdout.Level)
+		assert.Equal(t, "stdout", stdout.Data[CommandStreamField])
+		assert.Equal(t, cmd.Process.Pid, stdout.Data[CommandPIDField])
+
+		stderr := messages["err"]
+		assert.Equal(t, Warn, stderr.Level)
+		assert.Equal(t, "stderr", stderr.Data[CommandStreamField])
+
+		exited := messages["process exited"]
+		assert.Equal(t, Info, exited.Level)
+		assert.Equal(t, 0, exited.Data[CommandExitCodeField])
+		assert.IsType(t, time.Duration(0), exited.Data[CommandDurationField])
+	})
+
+	t.Run("logs a failed exit at error with the exit code, within the segment of the context", func(t *testing.T) {
+		if _, err := exec.LookPath("sh"); err != nil {
+			t.Skip("sh not available")
+		}
+
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Times(3)
+
+		logger := New(mockImpl.Impl)
+		ctx, _ := logger.Segment(context.Background(), "flashing")
+
+		err := logger.Command(ctx, exec.Command("sh", "-c", "exit 3"), Info, Warn, Source("flasher")).Run()
+		assert.Error(t, err)
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+
+		exited := mockImpl.Calls[2].Arguments.Get(1).(Message)
+		assert.Equal(t, "process exited", exited.Message)
+		assert.Equal(t, Error, exited.Level)
+		assert.Equal(t, "flasher", exited.Source)
+		assert.Equal(t, 3, exited.Data[CommandExitCodeField])
+		assert.Equal(t, uint64(1), exited.Data[SegmentIDField])
+		assert.NotContains(t, exited.Data, errField)
+	})
+
+	t.Run("logs a failure to start at error", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Once()
+
+		logger := New(mockImpl.Impl)
+
+		err := logger.Command(context.Background(), exec.Command("/nonexistent/command"), Info, Warn).Run()
+		assert.Error(t, err)
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+
+		failed := mockImpl.Calls[0].Arguments.Get(1).(Message)
+		assert.Equal(t, "process failed to start", failed.Message)
+		assert.Equal(t, Error, failed.Level)
+		assert.Contains(t, failed.Data, errField)
+	})
+}
@@ -0,0 +1,83 @@
+package logwrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorHandler handles errors reported by implementations which have failed to deliver a message, such as a write to a
+// full disk or closed socket.
+type ErrorHandler func(error)
+
+const contextKeyErrorHandler = "_ShimmeringBeeLogErrorHandler"
+
+// DefaultErrorReportInterval is the interval the default error handler is rate limited to.
+const DefaultErrorReportInterval = 10 * time.Second
+
+var defaultErrorHandler atomic.Value
+
+func init() {
+	SetDefaultErrorHandler(RateLimitedErrorHandler(func(err error) {
+		_, _ = fmt.Fprintf(os.Stderr, "logwrap: %v\n", err)
+	}, DefaultErrorReportInterval))
+}
+
+// SetDefaultErrorHandler sets the error handler used when an implementation reports an error and the context has no
+// error handler. By default errors are written to standard error, rate limited to one every
+// DefaultErrorReportInterval.
+func SetDefaultErrorHandler(handler ErrorHandler) {
+	defaultErrorHandler.Store(handler)
+}
+
+// ContextWithErrorHandler adds an error handler to the context, implementations called with this context report errors
+// to it rather than the default error handler. This permits wrapping implementations to observe the failures of their
+// destinations.
+func ContextWithErrorHandler(ctx context.Context, handler ErrorHandler) context.Context {
+	return context.WithValue(ctx, contextKey{base: contextKeyErrorHandler}, handler)
+}
+
+// ReportError should be called by implementations which fail to deliver a message, the error is passed to the error
+// handler within the context if present, otherwise to the default error handler.
+func ReportError(ctx context.Context, err error) {
+	if handler, ok := ctx.Value(contextKey{base: contextKeyErrorHandler}).(ErrorHandler); ok {
+		handler(err)
+		return
+	}
+
+	defaultErrorHandler.Load().(ErrorHandler)(err)
+}
+
+// RateLimitedErrorHandler wraps an error handler so that it is called at most once per interval, preventing a broken
+// implementation from flooding the handler. The number of errors suppressed since the last call is added to the error.
+func RateLimitedErrorHandler(handler ErrorHandler, interval time.Duration) ErrorHandler {
+	mutex := &sync.Mutex{}
+	var last time.Time
+	var suppressed uint64
+
+	return func(err error) {
+		mutex.Lock()
+
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) < interval {
+			suppressed++
+			mutex.Unlock()
+			return
+		}
+
+		count := suppressed
+		last = now
+		suppressed = 0
+
+		mutex.Unlock()
+
+		if count > 0 {
+			err = fmt.Errorf("%w (%d further errors suppressed)", err, count)
+		}
+
+		handler(err)
+	}
+}
@@ -0,0 +1,63 @@
+package logwrap
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReportError(t *testing.T) {
+	t.Run("reports errors to the handler in the context", func(t *testing.T) {
+		var reported []error
+		ctx := ContextWithErrorHandler(context.Background(), func(err error) {
+			reported = append(reported, err)
+		})
+
+		expectedErr := errors.New("failure")
+		ReportError(ctx, expectedErr)
+
+		assert.Equal(t, []error{expectedErr}, reported)
+	})
+
+	t.Run("reports errors to the default handler if the context has none", func(t *testing.T) {
+		previousHandler := defaultErrorHandler.Load().(ErrorHandler)
+		defer SetDefaultErrorHandler(previousHandler)
+
+		var reported []error
+		SetDefaultErrorHandler(func(err error) {
+			reported = append(reported, err)
+		})
+
+		expectedErr := errors.New("failure")
+		ReportError(context.Background(), expectedErr)
+
+		assert.Equal(t, []error{expectedErr}, reported)
+	})
+}
+
+func TestRateLimitedErrorHandler(t *testing.T) {
+	t.Run("calls the handler at most once per interval, reporting suppressed errors", func(t *testing.T) {
+		var reported []error
+		handler := RateLimitedErrorHandler(func(err error) {
+			reported = append(reported, err)
+		}, 20*time.Millisecond)
+
+		firstErr := errors.New("first")
+		handler(firstErr)
+		handler(errors.New("suppressed"))
+		handler(errors.New("suppressed"))
+
+		assert.Equal(t, []error{firstErr}, reported)
+
+		time.Sleep(25 * time.Millisecond)
+
+		lastErr := errors.New("last")
+		handler(lastErr)
+
+		assert.Len(t, reported, 2)
+		assert.True(t, errors.Is(reported[1], lastErr))
+		assert.Equal(t, "last (2 further errors suppressed)", reported[1].Error())
+	})
+}
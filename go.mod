@@ -3,6 +3,10 @@ module github.com/shimmeringbee/logwrap
 go 1.14
 
 require (
+	github.com/go-logr/logr v1.2.4
+	github.com/rs/zerolog v1.20.0
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.6.1
+	go.uber.org/zap v1.16.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
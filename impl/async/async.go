@@ -0,0 +1,345 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policy determines the behaviour when a message is logged while the queue is full.
+type Policy int
+
+const (
+	// Block waits for space in the queue, applying backpressure to the caller.
+	Block Policy = iota
+	// DropNewest discards the message being logged.
+	DropNewest
+	// DropOldest discards the oldest message in the queue to make space.
+	DropOldest
+	// DropBelowLevel discards the message being logged if it is less severe than the level set by DropLevel, more
+	// severe messages block.
+	DropBelowLevel
+)
+
+// Option is a configuration option for the asynchronous implementation.
+type Option func(*Async)
+
+// DefaultQueueSize is the default number of messages which may be queued.
+const DefaultQueueSize = 1024
+
+// DefaultReportInterval is the default interval at which the number of dropped messages is reported.
+const DefaultReportInterval = 10 * time.Second
+
+// DroppedField is the name of the field containing the number of dropped messages in a report.
+const DroppedField = "dropped"
+
+// QueueSize sets the number of messages which may be queued before the Policy applies.
+func QueueSize(size int) Option {
+	return func(a *Async) {
+		a.queueSize = size
+	}
+}
+
+// WhenFull sets the Policy applied when the queue is full, by default Block.
+func WhenFull(policy Policy) Option {
+	return func(a *Async) {
+		a.policy = policy
+	}
+}
+
+// DropLevel sets the level used by the DropBelowLevel policy, messages less severe than this are dropped when the queue
+// is full. By default Info.
+func DropLevel(level logwrap.LogLevel) Option {
+	return func(a *Async) {
+		a.dropLevel = level
+	}
+}
+
+// ReportInterval sets the interval at which a Warn message is sent to the destination reporting the number of messages
+// dropped since the last report, if any. An interval of zero disables reporting.
+func ReportInterval(interval time.Duration) Option {
+	return func(a *Async) {
+		a.reportInterval = interval
+	}
+}
+
+// NewAsyncSink initialises a new Async implementation as with NewAsync, flushing or closing it also flushes or closes
+// the destination once the queue has been drained.
+func NewAsyncSink(dest logwrap.Sink, options ...Option) *Async {
+	a := NewAsync(dest.Impl(), options...)
+	a.destLifecycle = dest
+
+	return a
+}
+
+// NewAsync initialises a new Async implementation which delivers messages to the destination on its own go routine,
+// the handle to be provided to logwrap should be obtained by calling Impl().
+//
+// Messages are cloned as they are queued, so later modifications by other implementations do not affect them. Panic and
+// Fatal messages are not queued, the queue is flushed and they are delivered on the callers go routine so that no
+// messages are lost when the destination panics or exits. Close should be called on shutdown to drain the queue.
+func NewAsync(dest logwrap.Impl, options ...Option) *Async {
+	a := &Async{
+		dest:           dest,
+		queueSize:      DefaultQueueSize,
+		policy:         Block,
+		dropLevel:      logwrap.Info,
+		reportInterval: DefaultReportInterval,
+		mutex:          &sync.RWMutex{},
+		pendingMutex:   &sync.Mutex{},
+		closing:        make(chan struct{}),
+		closeOnce:      &sync.Once{},
+		done:           make(chan struct{}),
+		idle:           make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	close(a.idle)
+	a.queue = make(chan entry, a.queueSize)
+
+	go a.run()
+
+	return a
+}
+
+// Async is a structure which provides a log implementation that queues messages for delivery on another go routine.
+type Async struct {
+	dest           logwrap.Impl
+	destLifecycle  logwrap.Lifecycle
+	queueSize      int
+	policy         Policy
+	dropLevel      logwrap.LogLevel
+	reportInterval time.Duration
+
+	queue     chan entry
+	mutex     *sync.RWMutex
+	closing   chan struct{}
+	closeOnce *sync.Once
+	done      chan struct{}
+
+	pendingMutex *sync.Mutex
+	pending      int
+	idle         chan struct{}
+
+	dropped         uint64
+	droppedReported uint64
+}
+
+type entry struct {
+	ctx     context.Context
+	message logwrap.Message
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (a *Async) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if message.Level == logwrap.Panic || message.Level == logwrap.Fatal {
+			_ = a.Flush(ctx)
+			a.dest(ctx, message)
+			return
+		}
+
+		if !a.isClosing() {
+			a.mutex.RLock()
+			queued := !a.isClosing() && a.enqueue(entry{ctx: ctx, message: message.Clone()})
+			a.mutex.RUnlock()
+
+			if queued {
+				return
+			}
+		}
+
+		a.dest(ctx, message)
+	}
+}
+
+// Dropped returns the total number of messages which have been dropped.
+func (a *Async) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Flush waits until all messages queued have been delivered to the destination, or the context is done.
+func (a *Async) Flush(ctx context.Context) error {
+	a.pendingMutex.Lock()
+	idle := a.idle
+	a.pendingMutex.Unlock()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if a.destLifecycle != nil {
+		return a.destLifecycle.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Close stops queuing messages and waits until all queued messages have been delivered, or the context is done.
+// Messages logged after Close, including those blocked waiting for space in the queue, are delivered on the callers go
+// routine. Async satisfies logwrap.Sink.
+func (a *Async) Close(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		close(a.closing)
+
+		go func() {
+			// Callers holding the read lock observe closing and release it, after which no more sends can occur.
+			a.mutex.Lock()
+			close(a.queue)
+			a.mutex.Unlock()
+		}()
+	})
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if a.destLifecycle != nil {
+		return a.destLifecycle.Close(ctx)
+	}
+
+	return nil
+}
+
+// enqueue places the entry on the queue, applying the policy if full. It returns false if the Async began closing while
+// waiting for space, in which case the entry has not been queued. Must be called with the read lock held.
+func (a *Async) enqueue(e entry) bool {
+	a.addPending()
+
+	select {
+	case a.queue <- e:
+		return true
+	default:
+	}
+
+	switch a.policy {
+	case DropNewest:
+		a.drop()
+		return true
+	case DropBelowLevel:
+		if e.message.Level > a.dropLevel {
+			a.drop()
+			return true
+		}
+
+		return a.send(e)
+	case DropOldest:
+		for {
+			select {
+			case a.queue <- e:
+				return true
+			default:
+			}
+
+			select {
+			case <-a.queue:
+				a.drop()
+			default:
+			}
+		}
+	default:
+		return a.send(e)
+	}
+}
+
+// send waits for space in the queue, giving up if the Async begins closing. Must be called with the read lock held.
+func (a *Async) send(e entry) bool {
+	select {
+	case a.queue <- e:
+		return true
+	case <-a.closing:
+		a.removePending()
+		return false
+	}
+}
+
+func (a *Async) isClosing() bool {
+	select {
+	case <-a.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+
+	var report <-chan time.Time
+	if a.reportInterval > 0 {
+		ticker := time.NewTicker(a.reportInterval)
+		defer ticker.Stop()
+		report = ticker.C
+	}
+
+	for {
+		select {
+		case e, ok := <-a.queue:
+			if !ok {
+				a.report()
+				return
+			}
+
+			a.dest(e.ctx, e.message)
+			a.removePending()
+		case <-report:
+			a.report()
+		}
+	}
+}
+
+// report sends a message to the destination with the number of messages dropped since the last report.
+func (a *Async) report() {
+	dropped := atomic.LoadUint64(&a.dropped)
+	count := dropped - a.droppedReported
+
+	if count == 0 {
+		return
+	}
+
+	a.droppedReported = dropped
+
+	a.dest(context.Background(), logwrap.Message{
+		Level:     logwrap.Warn,
+		Message:   fmt.Sprintf("%d messages dropped", count),
+		Data:      map[string]interface{}{DroppedField: count},
+		Timestamp: time.Now(),
+	})
+}
+
+func (a *Async) drop() {
+	atomic.AddUint64(&a.dropped, 1)
+	a.removePending()
+}
+
+func (a *Async) addPending() {
+	a.pendingMutex.Lock()
+	defer a.pendingMutex.Unlock()
+
+	if a.pending == 0 {
+		a.idle = make(chan struct{})
+	}
+
+	a.pending++
+}
+
+func (a *Async) removePending() {
+	a.pendingMutex.Lock()
+	defer a.pendingMutex.Unlock()
+
+	a.pending--
+
+	if a.pending == 0 {
+		close(a.idle)
+	}
+}
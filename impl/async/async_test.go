@@ -0,0 +1,228 @@
+package async
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// gatedImpl returns an implementation which blocks until the gate is closed, before passing messages to the capture.
+func gatedImpl(c *capture.Capture, gate chan struct{}) logwrap.Impl {
+	captureImpl := c.Impl()
+
+	return func(ctx context.Context, message logwrap.Message) {
+		<-gate
+		captureImpl(ctx, message)
+	}
+}
+
+func messagesText(c *capture.Capture) []string {
+	var text []string
+
+	for _, message := range c.Messages() {
+		text = append(text, message.Message)
+	}
+
+	return text
+}
+
+func TestAsync(t *testing.T) {
+	t.Run("delivers messages to the destination in order, flush waits for delivery", func(t *testing.T) {
+		c := capture.NewCapture()
+		a := NewAsync(c.Impl())
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "two"})
+
+		assert.NoError(t, a.Flush(context.Background()))
+		assert.Equal(t, []string{"one", "two"}, messagesText(c))
+	})
+
+	t.Run("flush returns the context error if the deadline passes", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate))
+
+		a.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, a.Flush(ctx))
+
+		close(gate)
+		assert.NoError(t, a.Close(context.Background()))
+	})
+
+	t.Run("drop newest discards messages logged while the queue is full", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate), QueueSize(1), WhenFull(DropNewest), ReportInterval(0))
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "in flight"})
+		assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "dropped"})
+
+		close(gate)
+		assert.NoError(t, a.Flush(context.Background()))
+
+		assert.Equal(t, []string{"in flight", "queued"}, messagesText(c))
+		assert.Equal(t, uint64(1), a.Dropped())
+	})
+
+	t.Run("drop oldest discards queued messages to make space", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate), QueueSize(1), WhenFull(DropOldest), ReportInterval(0))
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "in flight"})
+		assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "dropped"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+
+		close(gate)
+		assert.NoError(t, a.Flush(context.Background()))
+
+		assert.Equal(t, []string{"in flight", "queued"}, messagesText(c))
+		assert.Equal(t, uint64(1), a.Dropped())
+	})
+
+	t.Run("drop below level discards less severe messages while the queue is full", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate), QueueSize(1), WhenFull(DropBelowLevel), DropLevel(logwrap.Warn), ReportInterval(0))
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "in flight"})
+		assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Debug, Message: "dropped"})
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(gate)
+		}()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error, Message: "blocked"})
+		assert.NoError(t, a.Flush(context.Background()))
+
+		assert.Equal(t, []string{"in flight", "queued", "blocked"}, messagesText(c))
+		assert.Equal(t, uint64(1), a.Dropped())
+	})
+
+	t.Run("periodically reports the number of dropped messages", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate), QueueSize(1), WhenFull(DropNewest), ReportInterval(5*time.Millisecond))
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "in flight"})
+		assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "dropped"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "dropped"})
+
+		close(gate)
+
+		assert.Eventually(t, func() bool { return len(c.Messages()) == 3 }, time.Second, time.Millisecond)
+
+		report := c.Messages()[2]
+		assert.Equal(t, logwrap.Warn, report.Level)
+		assert.Equal(t, "2 messages dropped", report.Message)
+		assert.Equal(t, uint64(2), report.Data[DroppedField])
+	})
+
+	t.Run("panic and fatal messages are delivered on the callers go routine after flushing", func(t *testing.T) {
+		c := capture.NewCapture()
+		a := NewAsync(c.Impl())
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "fatal"})
+
+		assert.Equal(t, []string{"queued", "fatal"}, messagesText(c))
+	})
+
+	t.Run("close drains the queue and later messages are delivered directly", func(t *testing.T) {
+		c := capture.NewCapture()
+		a := NewAsync(c.Impl())
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+
+		assert.NoError(t, a.Close(context.Background()))
+		assert.Equal(t, []string{"queued"}, messagesText(c))
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "direct"})
+		assert.Equal(t, []string{"queued", "direct"}, messagesText(c))
+
+		assert.NoError(t, a.Close(context.Background()))
+	})
+
+	t.Run("close honours the context deadline while callers are blocked on a full queue", func(t *testing.T) {
+		c := capture.NewCapture()
+		gate := make(chan struct{})
+		a := NewAsync(gatedImpl(c, gate), QueueSize(1), ReportInterval(0))
+
+		impl := a.Impl()
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "delivering"})
+		assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond)
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+
+		blocked := make(chan struct{})
+		go func() {
+			defer close(blocked)
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "blocked"})
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		assert.Equal(t, context.DeadlineExceeded, a.Close(ctx))
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+		close(gate)
+		<-blocked
+
+		assert.NoError(t, a.Close(context.Background()))
+		assert.ElementsMatch(t, []string{"delivering", "queued", "blocked"}, messagesText(c))
+	})
+}
+
+func TestNewAsyncSink(t *testing.T) {
+	t.Run("flush and close drain the queue and are then propagated to the destination", func(t *testing.T) {
+		c := capture.NewCapture()
+
+		flushed := false
+		closed := false
+
+		a := NewAsyncSink(logwrap.WithLifecycle(c.Impl(), func(context.Context) error {
+			flushed = len(c.Messages()) == 1
+			return nil
+		}, func(context.Context) error {
+			closed = true
+			return nil
+		}))
+
+		var sink logwrap.Sink = a
+		sink.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "queued"})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.True(t, flushed)
+
+		assert.NoError(t, sink.Close(context.Background()))
+		assert.True(t, closed)
+	})
+}
@@ -21,13 +21,14 @@ type Capture struct {
 	messages []logwrap.Message
 }
 
-// Impl returns an implementation that can be passed to logwrap.
+// Impl returns an implementation that can be passed to logwrap. Messages are cloned as they are captured, so later
+// modifications by other implementations are not reflected in the captured messages.
 func (c *Capture) Impl() logwrap.Impl {
 	return func(ctx context.Context, message logwrap.Message) {
 		c.mutex.Lock()
 		defer c.mutex.Unlock()
 
-		c.messages = append(c.messages, message)
+		c.messages = append(c.messages, message.Clone())
 	}
 }
 
@@ -48,4 +48,15 @@ func TestCapture(t *testing.T) {
 		m = c.Messages()
 		assert.Empty(t, m)
 	})
+
+	t.Run("captured messages are not affected by later modification of the data", func(t *testing.T) {
+		c := NewCapture()
+
+		data := map[string]interface{}{"key": "value"}
+		c.Impl()(context.TODO(), logwrap.Message{Data: data})
+
+		data["key"] = "changed"
+
+		assert.Equal(t, "value", c.Messages()[0].Data["key"])
+	})
 }
@@ -0,0 +1,261 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/shimmeringbee/logwrap/impl/writer"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option is a configuration option for the console implementation.
+type Option func(*config)
+
+type config struct {
+	colour      bool
+	timeFormat  string
+	sourceWidth int
+}
+
+// DefaultTimeFormat is the default layout used to render the message timestamp.
+const DefaultTimeFormat = "15:04:05.000"
+
+// DefaultSourceWidth is the default width the source is padded to, to keep messages aligned.
+const DefaultSourceWidth = 12
+
+// Colour forces colours to be enabled or disabled, overriding detection of whether the writer is a terminal.
+func Colour(enabled bool) Option {
+	return func(c *config) {
+		c.colour = enabled
+	}
+}
+
+// TimeFormat sets the layout, as understood by time.Format, used to render the message timestamp.
+func TimeFormat(layout string) Option {
+	return func(c *config) {
+		c.timeFormat = layout
+	}
+}
+
+// SourceWidth sets the width the source is padded to, sources longer than this are not truncated.
+func SourceWidth(width int) Option {
+	return func(c *config) {
+		c.sourceWidth = width
+	}
+}
+
+const (
+	colourReset   = "\x1b[0m"
+	colourDim     = "\x1b[2m"
+	colourRed     = "\x1b[31m"
+	colourGreen   = "\x1b[32m"
+	colourYellow  = "\x1b[33m"
+	colourBlue    = "\x1b[34m"
+	colourMagenta = "\x1b[35m"
+	colourCyan    = "\x1b[36m"
+	colourBoldRed = "\x1b[1;31m"
+)
+
+const indentWidth = 2
+const segmentStartMarker = "┌ "
+const segmentEndMarker = "└ "
+
+// Formatter returns a format.Formatter which renders each message as a single line of aligned timestamp, level, source,
+// message and key=value data, intended for developers running applications locally. Colours are disabled unless enabled
+// with the Colour option.
+//
+// Messages within a Segment are indented by the depth of the segment, with the start and end of segments marked.
+// Values of type logwrap.SourceLocation are rendered as a short file and line, and values spanning multiple lines (such
+// as stack traces) are written on their own indented lines following the message. All other values are rendered with
+// format.String.
+func Formatter(options ...Option) format.Formatter {
+	cfg := config{
+		timeFormat:  DefaultTimeFormat,
+		sourceWidth: DefaultSourceWidth,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	return &console{
+		config: cfg,
+		mutex:  &sync.Mutex{},
+		depths: map[uint64]int{},
+	}
+}
+
+// Wrap implements a human friendly console writer, each message is written to the io.Writer as rendered by Formatter.
+//
+// Colours are enabled if the writer is a terminal and the NO_COLOR environment variable is not set, this can be
+// overridden with the Colour option.
+func Wrap(w io.Writer, options ...Option) logwrap.Impl {
+	options = append([]Option{Colour(isTerminal(w) && os.Getenv("NO_COLOR") == "")}, options...)
+	return writer.Writer(w, Formatter(options...))
+}
+
+type console struct {
+	config
+	mutex  *sync.Mutex
+	depths map[uint64]int
+}
+
+// Format renders the message, tracking the depth of any segment it is a member of.
+func (c *console) Format(message logwrap.Message) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	indent, marker := c.segmentLayout(message)
+
+	buffer := &bytes.Buffer{}
+
+	buffer.WriteString(c.paint(colourDim, message.Timestamp.Format(c.timeFormat)))
+	buffer.WriteByte(' ')
+	buffer.WriteString(c.paint(levelColour(message.Level), fmt.Sprintf("%-5s", message.Level.String())))
+	buffer.WriteByte(' ')
+	buffer.WriteString(c.paint(colourCyan, fmt.Sprintf("%-*s", c.sourceWidth, message.Source)))
+	buffer.WriteByte(' ')
+	buffer.WriteString(strings.Repeat(" ", indent*indentWidth))
+	buffer.WriteString(marker)
+	buffer.WriteString(message.Message)
+
+	var multiline []string
+
+	for _, key := range format.SortedKeys(message.Data) {
+		if key == logwrap.SegmentField || key == logwrap.ParentSegmentIDField {
+			continue
+		}
+
+		value := renderValue(message.Data[key])
+
+		if strings.Contains(value, "\n") {
+			multiline = append(multiline, key)
+			continue
+		}
+
+		buffer.WriteByte(' ')
+		buffer.WriteString(c.paint(colourDim, key+"="))
+		buffer.WriteString(quoteIfNeeded(value))
+	}
+
+	buffer.WriteByte('\n')
+
+	for _, key := range multiline {
+		padding := strings.Repeat(" ", (indent+2)*indentWidth)
+
+		buffer.WriteString(padding)
+		buffer.WriteString(c.paint(colourDim, key+":"))
+		buffer.WriteByte('\n')
+
+		for _, line := range strings.Split(strings.TrimRight(renderValue(message.Data[key]), "\n"), "\n") {
+			buffer.WriteString(padding)
+			buffer.WriteString(strings.Repeat(" ", indentWidth))
+			buffer.WriteString(line)
+			buffer.WriteByte('\n')
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// segmentLayout determines the indentation and marker of a message, tracking the depth of open segments. Must be called
+// with the mutex held.
+func (c *console) segmentLayout(message logwrap.Message) (int, string) {
+	segmentID, ok := message.Data[logwrap.SegmentIDField].(uint64)
+	if !ok {
+		return 0, ""
+	}
+
+	switch message.Data[logwrap.SegmentField] {
+	case logwrap.SegmentStartValue:
+		depth := 0
+
+		if parentID, ok := message.Data[logwrap.ParentSegmentIDField].(uint64); ok {
+			if parentDepth, found := c.depths[parentID]; found {
+				depth = parentDepth + 1
+			}
+		}
+
+		c.depths[segmentID] = depth
+		return depth, segmentStartMarker
+	case logwrap.SegmentEndValue:
+		depth := c.depths[segmentID]
+		delete(c.depths, segmentID)
+		return depth, segmentEndMarker
+	default:
+		return c.depths[segmentID] + 1, ""
+	}
+}
+
+func (c *console) paint(colour string, s string) string {
+	if !c.colour {
+		return s
+	}
+
+	return colour + s + colourReset
+}
+
+func levelColour(level logwrap.LogLevel) string {
+	switch level {
+	case logwrap.Panic, logwrap.Fatal:
+		return colourBoldRed
+	case logwrap.Error:
+		return colourRed
+	case logwrap.Warn:
+		return colourYellow
+	case logwrap.Info:
+		return colourGreen
+	case logwrap.Debug:
+		return colourBlue
+	default:
+		return colourMagenta
+	}
+}
+
+func renderValue(value interface{}) string {
+	if location, ok := value.(logwrap.SourceLocation); ok {
+		return fmt.Sprintf("%s:%d", shortFile(location.File), location.Line)
+	}
+
+	return format.String(value, time.RFC3339Nano)
+}
+
+// shortFile reduces a file path to its final directory and file name.
+func shortFile(file string) string {
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		return name
+	}
+
+	return filepath.Join(filepath.Base(dir), name)
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+// isTerminal reports if the writer is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,120 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrap(t *testing.T) {
+	expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("writes aligned timestamp, level, source, message and sorted key value data", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, SourceWidth(8))
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Data:      map[string]interface{}{"b": "with space", "a": 1, "err": errors.New("failure")},
+			Timestamp: expectedTime,
+			Source:    "source",
+		})
+
+		expected := "12:30:00.000 WARN  source   message a=1 b=\"with space\" err=failure\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("colours are disabled when not writing to a terminal", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error, Message: "message"})
+
+		assert.NotContains(t, outputBuffer.String(), "\x1b[")
+	})
+
+	t.Run("colours can be forced on", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, Colour(true))
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error, Message: "message"})
+
+		assert.Contains(t, outputBuffer.String(), colourRed+"ERROR"+colourReset)
+	})
+
+	t.Run("segments are marked and their contents indented by depth", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		logger := logwrap.New(Wrap(&outputBuffer, SourceWidth(0), TimeFormat("")))
+
+		ctx, end := logger.Segment(context.Background(), "outer")
+		subCtx, subEnd := logger.Segment(ctx, "inner")
+		logger.Info(subCtx, "detail")
+		subEnd()
+		end()
+
+		expected := []string{
+			" INFO   ┌ outer segmentID=1",
+			" INFO     ┌ inner segmentID=2",
+			" INFO       detail segmentID=2",
+			" INFO     └ inner segmentID=2",
+			" INFO   └ outer segmentID=1",
+		}
+
+		assert.Equal(t, strings.Join(expected, "\n")+"\n", outputBuffer.String())
+	})
+
+	t.Run("source locations are shortened and multiline values are written on following lines", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, SourceWidth(0), TimeFormat(""))
+
+		impl(context.Background(), logwrap.Message{
+			Level:   logwrap.Error,
+			Message: "message",
+			Data: map[string]interface{}{
+				logwrap.SourceTraceField: logwrap.SourceLocation{Function: "main.main", File: "/src/app/main.go", Line: 42},
+				"stack":                  "goroutine 1:\nmain.main()\n",
+			},
+		})
+
+		expected := " ERROR  message sourceTrace=app/main.go:42\n" +
+			"    stack:\n" +
+			"      goroutine 1:\n" +
+			"      main.main()\n"
+
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("panic level messages are written and then panic", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		assert.Panics(t, func() {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+}
+
+func Test_isTerminal(t *testing.T) {
+	t.Run("non file writers are not terminals", func(t *testing.T) {
+		assert.False(t, isTerminal(&bytes.Buffer{}))
+	})
+
+	t.Run("regular files are not terminals", func(t *testing.T) {
+		file, err := ioutil.TempFile("", "console")
+		assert.NoError(t, err)
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		assert.False(t, isTerminal(file))
+	})
+}
@@ -0,0 +1,302 @@
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option is a configuration option for the deduplicating implementation.
+type Option func(*Dedupe)
+
+// DefaultWindow is the default duration repeated messages are collapsed over.
+const DefaultWindow = time.Minute
+
+// DefaultMaximumRuns is the default number of runs of repeated messages tracked at once.
+const DefaultMaximumRuns = 1000
+
+// RepeatedField is the name of the field containing the number of suppressed repeats in a summary.
+const RepeatedField = "repeated"
+
+// RepeatedOverField is the name of the field containing the duration the repeats occurred over in a summary.
+const RepeatedOverField = "repeatedOver"
+
+// now returns the current time, it is a variable to permit testing.
+var now = time.Now
+
+// Window sets the duration, measured from the first occurrence, over which repeats of a message are collapsed. When the
+// window closes a summary of the repeats is emitted, and the next occurrence is emitted as normal.
+func Window(window time.Duration) Option {
+	return func(d *Dedupe) {
+		d.window = window
+	}
+}
+
+// KeyFields adds the values of the named data fields to the key which identifies repeated messages, by default only
+// the level, source and message text are used.
+func KeyFields(fields ...string) Option {
+	return func(d *Dedupe) {
+		d.keyFields = append(d.keyFields, fields...)
+	}
+}
+
+// Consecutive causes only consecutive repeats to be collapsed, a message which differs ends any run of repeats before
+// its window closes.
+func Consecutive() Option {
+	return func(d *Dedupe) {
+		d.consecutive = true
+	}
+}
+
+// MaximumRuns sets the number of runs of repeated messages tracked at once, bounding memory use. When a new run would
+// exceed the maximum, the oldest run is ended early and its summary emitted. A maximum of zero removes the limit.
+func MaximumRuns(maximum int) Option {
+	return func(d *Dedupe) {
+		d.maximumRuns = maximum
+	}
+}
+
+// NewDedupeSink initialises a new Dedupe implementation as with NewDedupe, flushing or closing it also flushes or
+// closes the destination once pending summaries have been emitted.
+func NewDedupeSink(dest logwrap.Sink, options ...Option) *Dedupe {
+	d := NewDedupe(dest.Impl(), options...)
+	d.destLifecycle = dest
+
+	return d
+}
+
+// NewDedupe initialises a new Dedupe implementation which collapses repeated messages before sending them to the
+// destination, the handle to be provided to logwrap should be obtained by calling Impl().
+//
+// The first occurrence of a message is sent immediately, further occurrences with the same key within the window are
+// suppressed. When the window closes, or the run ends if Consecutive, a summary of the same level and source is sent
+// with the message text suffixed by "repeated N times over D", carrying the data of the last repeat along with
+// RepeatedField and RepeatedOverField. Panic and Fatal messages are never suppressed.
+func NewDedupe(dest logwrap.Impl, options ...Option) *Dedupe {
+	d := &Dedupe{
+		dest:        dest,
+		window:      DefaultWindow,
+		maximumRuns: DefaultMaximumRuns,
+		mutex:       &sync.Mutex{},
+		runs:        map[key]*run{},
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+// Dedupe is a structure which provides a log implementation that collapses repeated messages.
+type Dedupe struct {
+	dest          logwrap.Impl
+	destLifecycle logwrap.Lifecycle
+	window        time.Duration
+	keyFields     []string
+	consecutive   bool
+	maximumRuns   int
+
+	mutex  *sync.Mutex
+	runs   map[key]*run
+	closed bool
+}
+
+type key struct {
+	level   logwrap.LogLevel
+	source  string
+	message string
+	fields  string
+}
+
+type run struct {
+	first   time.Time
+	last    time.Time
+	count   uint64
+	message logwrap.Message
+	timer   *time.Timer
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (d *Dedupe) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if message.Level == logwrap.Panic || message.Level == logwrap.Fatal {
+			_ = d.Flush(ctx)
+			d.dest(ctx, message)
+			return
+		}
+
+		k := d.key(message)
+
+		d.mutex.Lock()
+
+		if d.closed {
+			d.mutex.Unlock()
+			d.dest(ctx, message)
+			return
+		}
+
+		var ended []*run
+
+		if d.consecutive {
+			for otherKey, other := range d.runs {
+				if otherKey != k {
+					ended = append(ended, d.end(otherKey, other))
+				}
+			}
+		}
+
+		current := now()
+
+		if r, found := d.runs[k]; found {
+			r.count++
+			r.last = current
+			r.message = message.Clone()
+			d.mutex.Unlock()
+
+			d.summarise(ended)
+			return
+		}
+
+		if d.maximumRuns > 0 && len(d.runs) >= d.maximumRuns {
+			ended = append(ended, d.endOldest())
+		}
+
+		r := &run{first: current, last: current}
+		r.timer = time.AfterFunc(d.window, func() {
+			d.expire(k, r)
+		})
+		d.runs[k] = r
+
+		d.mutex.Unlock()
+
+		d.summarise(ended)
+		d.dest(ctx, message)
+	}
+}
+
+// Flush ends all runs of repeated messages, sending their summaries to the destination.
+func (d *Dedupe) Flush(ctx context.Context) error {
+	d.mutex.Lock()
+	ended := d.endAll()
+	d.mutex.Unlock()
+
+	d.summarise(ended)
+
+	if d.destLifecycle != nil {
+		return d.destLifecycle.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Close flushes all pending summaries, messages logged after Close are sent to the destination without being
+// deduplicated. Dedupe satisfies logwrap.Sink.
+func (d *Dedupe) Close(ctx context.Context) error {
+	d.mutex.Lock()
+	d.closed = true
+	ended := d.endAll()
+	d.mutex.Unlock()
+
+	d.summarise(ended)
+
+	if d.destLifecycle != nil {
+		return d.destLifecycle.Close(ctx)
+	}
+
+	return nil
+}
+
+// key builds the key identifying repeats of the message.
+func (d *Dedupe) key(message logwrap.Message) key {
+	k := key{level: message.Level, source: message.Source, message: message.Message}
+
+	if len(d.keyFields) > 0 {
+		values := make([]string, len(d.keyFields))
+
+		for i, field := range d.keyFields {
+			if value, found := message.Data[field]; found {
+				values[i] = fmt.Sprintf("%#v", value)
+			}
+		}
+
+		k.fields = strings.Join(values, "\x00")
+	}
+
+	return k
+}
+
+// expire ends a run when its window closes, if it has not already been ended.
+func (d *Dedupe) expire(k key, r *run) {
+	d.mutex.Lock()
+
+	if d.runs[k] != r {
+		d.mutex.Unlock()
+		return
+	}
+
+	ended := d.end(k, r)
+	d.mutex.Unlock()
+
+	d.summarise([]*run{ended})
+}
+
+// endAll ends every run. Must be called with the mutex held.
+func (d *Dedupe) endAll() []*run {
+	var ended []*run
+
+	for k, r := range d.runs {
+		ended = append(ended, d.end(k, r))
+	}
+
+	return ended
+}
+
+// endOldest ends the run which started first. Must be called with the mutex held and at least one run.
+func (d *Dedupe) endOldest() *run {
+	var oldestKey key
+	var oldest *run
+
+	for k, r := range d.runs {
+		if oldest == nil || r.first.Before(oldest.first) {
+			oldestKey, oldest = k, r
+		}
+	}
+
+	return d.end(oldestKey, oldest)
+}
+
+// end removes a run and stops its timer. Must be called with the mutex held.
+func (d *Dedupe) end(k key, r *run) *run {
+	r.timer.Stop()
+	delete(d.runs, k)
+
+	return r
+}
+
+// summarise sends a summary of each run which had repeats to the destination.
+func (d *Dedupe) summarise(runs []*run) {
+	for _, r := range runs {
+		if r.count == 0 {
+			continue
+		}
+
+		over := r.last.Sub(r.first)
+
+		summary := r.message
+		summary.Message = fmt.Sprintf("%s (repeated %d times over %s)", summary.Message, r.count, over)
+		summary.Timestamp = now()
+
+		if summary.Data == nil {
+			summary.Data = map[string]interface{}{}
+		}
+
+		summary.Data[RepeatedField] = r.count
+		summary.Data[RepeatedOverField] = over
+
+		d.dest(context.Background(), summary)
+	}
+}
@@ -0,0 +1,157 @@
+package dedupe
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDedupe(t *testing.T) {
+	t.Run("repeats within the window are suppressed and summarised on flush", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		c := capture.NewCapture()
+		d := NewDedupe(c.Impl())
+		impl := d.Impl()
+
+		for i := 0; i < 5; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Source: "device", Message: "flapping", Data: map[string]interface{}{"attempt": i}})
+			current = current.Add(time.Second)
+		}
+
+		assert.Len(t, c.Messages(), 1)
+
+		assert.NoError(t, d.Flush(context.Background()))
+
+		messages := c.Messages()
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "flapping (repeated 4 times over 4s)", messages[1].Message)
+		assert.Equal(t, logwrap.Warn, messages[1].Level)
+		assert.Equal(t, "device", messages[1].Source)
+		assert.Equal(t, uint64(4), messages[1].Data[RepeatedField])
+		assert.Equal(t, 4*time.Second, messages[1].Data[RepeatedOverField])
+		assert.Equal(t, 4, messages[1].Data["attempt"])
+	})
+
+	t.Run("messages differing by level, source, message or key fields are not collapsed", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := NewDedupe(c.Impl(), KeyFields("device")).Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Source: "source", Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "other"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: map[string]interface{}{"device": "a"}})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: map[string]interface{}{"device": "b"}})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: map[string]interface{}{"device": "b", "other": 1}})
+
+		assert.Len(t, c.Messages(), 6)
+	})
+
+	t.Run("the oldest run is ended and summarised when the maximum runs is exceeded", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		c := capture.NewCapture()
+		d := NewDedupe(c.Impl(), MaximumRuns(2))
+		impl := d.Impl()
+
+		for _, text := range []string{"one", "two", "one", "three"} {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: text})
+			current = current.Add(time.Second)
+		}
+
+		var text []string
+		for _, message := range c.Messages() {
+			text = append(text, message.Message)
+		}
+
+		assert.Equal(t, []string{"one", "two", "one (repeated 1 times over 2s)", "three"}, text)
+		assert.Len(t, d.runs, 2)
+	})
+
+	t.Run("a summary is emitted when the window closes", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := NewDedupe(c.Impl(), Window(10*time.Millisecond)).Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+
+		assert.Eventually(t, func() bool {
+			return len(c.Messages()) == 2
+		}, time.Second, time.Millisecond)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+
+		assert.Len(t, c.Messages(), 3)
+		assert.Equal(t, "message", c.Messages()[2].Message)
+	})
+
+	t.Run("no summary is emitted if there were no repeats", func(t *testing.T) {
+		c := capture.NewCapture()
+		d := NewDedupe(c.Impl())
+
+		d.Impl()(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		assert.NoError(t, d.Flush(context.Background()))
+
+		assert.Len(t, c.Messages(), 1)
+	})
+
+	t.Run("consecutive mode ends a run when a different message is logged", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := NewDedupe(c.Impl(), Consecutive()).Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "two"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "one"})
+
+		messages := c.Messages()
+		assert.Len(t, messages, 4)
+		assert.Equal(t, uint64(1), messages[1].Data[RepeatedField])
+		assert.Equal(t, "two", messages[2].Message)
+		assert.Equal(t, "one", messages[3].Message)
+	})
+
+	t.Run("panic messages are never suppressed and pending summaries are emitted first", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := NewDedupe(c.Impl()).Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "two"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "two"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "one"})
+
+		messages := c.Messages()
+		assert.Len(t, messages, 4)
+		assert.Equal(t, uint64(1), messages[2].Data[RepeatedField])
+		assert.Equal(t, logwrap.Panic, messages[3].Level)
+	})
+
+	t.Run("closing emits pending summaries and closes the destination", func(t *testing.T) {
+		c := capture.NewCapture()
+		closed := false
+
+		d := NewDedupeSink(logwrap.WithLifecycle(c.Impl(), nil, func(context.Context) error {
+			closed = true
+			return nil
+		}))
+
+		d.Impl()(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		d.Impl()(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+
+		assert.NoError(t, d.Close(context.Background()))
+		assert.Len(t, c.Messages(), 2)
+		assert.True(t, closed)
+
+		d.Impl()(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		d.Impl()(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+		assert.Len(t, c.Messages(), 4)
+	})
+}
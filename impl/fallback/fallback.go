@@ -0,0 +1,172 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+	"time"
+)
+
+// Option is a configuration option for the fallback implementation.
+type Option func(*Fallback)
+
+// DefaultFailureThreshold is the default number of consecutive failures of the primary before switching to the
+// secondary.
+const DefaultFailureThreshold = 3
+
+// DefaultRetryInterval is the default duration the secondary is used for before the primary is retried.
+const DefaultRetryInterval = 30 * time.Second
+
+// FailureThreshold sets the number of consecutive failures of the primary before switching to the secondary.
+func FailureThreshold(failures int) Option {
+	return func(f *Fallback) {
+		f.threshold = failures
+	}
+}
+
+// RetryInterval sets the duration the secondary is used for before the primary is retried.
+func RetryInterval(interval time.Duration) Option {
+	return func(f *Fallback) {
+		f.retryInterval = interval
+	}
+}
+
+// NewFallback initialises a new Fallback implementation, the handle to be provided to logwrap should be obtained by
+// calling Impl().
+//
+// Messages are sent to the primary, which must report delivery failures with logwrap.ReportError. A message the primary
+// fails to deliver is sent to the secondary, and once the primary has failed the threshold number of consecutive times
+// all messages are sent to the secondary until the retry interval has passed. Switching between destinations is
+// reported with logwrap.ReportError.
+//
+// A message is only resent to the secondary if the primary reports the failure before returning. Failures reported
+// later, such as by a primary which delivers on another go routine like async, still count towards the threshold but
+// the message itself is not resent.
+func NewFallback(primary logwrap.Impl, secondary logwrap.Impl, options ...Option) *Fallback {
+	f := &Fallback{
+		primary:       primary,
+		secondary:     secondary,
+		threshold:     DefaultFailureThreshold,
+		retryInterval: DefaultRetryInterval,
+		mutex:         &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	return f
+}
+
+// Fallback is a structure which provides a log implementation that switches to a secondary destination when the primary
+// keeps failing.
+type Fallback struct {
+	primary       logwrap.Impl
+	secondary     logwrap.Impl
+	threshold     int
+	retryInterval time.Duration
+
+	mutex    *sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (f *Fallback) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if f.usingSecondary() {
+			f.secondary(ctx, message)
+			return
+		}
+
+		result := &attempt{}
+
+		f.primary(logwrap.ContextWithErrorHandler(ctx, func(err error) {
+			if !result.report(err) {
+				f.recordFailure(ctx, err)
+			}
+		}), message)
+
+		primaryErr := result.finish()
+
+		if primaryErr == nil {
+			f.recordSuccess(ctx)
+			return
+		}
+
+		f.recordFailure(ctx, primaryErr)
+		f.secondary(ctx, message)
+	}
+}
+
+// attempt records the outcome of a single delivery to the primary, which may report errors from another go routine.
+type attempt struct {
+	mutex    sync.Mutex
+	err      error
+	finished bool
+}
+
+// report records an error reported by the primary, returning false if it arrived after the attempt was complete.
+func (a *attempt) report(err error) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.finished {
+		return false
+	}
+
+	if a.err == nil {
+		a.err = err
+	}
+
+	return true
+}
+
+// finish marks the attempt as complete, returning the first error reported during it.
+func (a *attempt) finish() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.finished = true
+	return a.err
+}
+
+// UsingSecondary reports if messages are currently being sent to the secondary.
+func (f *Fallback) UsingSecondary() bool {
+	return f.usingSecondary()
+}
+
+func (f *Fallback) usingSecondary() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return !f.retryAt.IsZero() && time.Now().Before(f.retryAt)
+}
+
+func (f *Fallback) recordSuccess(ctx context.Context) {
+	f.mutex.Lock()
+	recovered := !f.retryAt.IsZero()
+	f.failures = 0
+	f.retryAt = time.Time{}
+	f.mutex.Unlock()
+
+	if recovered {
+		logwrap.ReportError(ctx, errors.New("fallback: primary recovered, switching back from secondary"))
+	}
+}
+
+func (f *Fallback) recordFailure(ctx context.Context, err error) {
+	f.mutex.Lock()
+	f.failures++
+	switching := f.failures >= f.threshold
+	if switching {
+		f.retryAt = time.Now().Add(f.retryInterval)
+	}
+	f.mutex.Unlock()
+
+	if switching {
+		logwrap.ReportError(ctx, fmt.Errorf("fallback: primary failed, switching to secondary for %s: %w", f.retryInterval, err))
+	}
+}
@@ -0,0 +1,98 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/async"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// failingImpl returns an implementation which reports an error while failing is set, otherwise it passes messages to the
+// capture.
+func failingImpl(c *capture.Capture, failing *bool) logwrap.Impl {
+	captureImpl := c.Impl()
+
+	return func(ctx context.Context, message logwrap.Message) {
+		if *failing {
+			logwrap.ReportError(ctx, errors.New("failure"))
+			return
+		}
+
+		captureImpl(ctx, message)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	t.Run("messages are sent to the primary while it succeeds", func(t *testing.T) {
+		primary := capture.NewCapture()
+		secondary := capture.NewCapture()
+		failing := false
+
+		f := NewFallback(failingImpl(primary, &failing), secondary.Impl())
+		f.Impl()(context.Background(), logwrap.Message{Message: "message"})
+
+		assert.Len(t, primary.Messages(), 1)
+		assert.Empty(t, secondary.Messages())
+	})
+
+	t.Run("messages which fail on the primary are sent to the secondary, switching after consecutive failures", func(t *testing.T) {
+		primary := capture.NewCapture()
+		secondary := capture.NewCapture()
+		failing := true
+
+		var reported []error
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported = append(reported, err)
+		})
+
+		f := NewFallback(failingImpl(primary, &failing), secondary.Impl(), FailureThreshold(2), RetryInterval(20*time.Millisecond))
+		impl := f.Impl()
+
+		impl(ctx, logwrap.Message{Message: "one"})
+		assert.False(t, f.UsingSecondary())
+		assert.Empty(t, reported)
+
+		impl(ctx, logwrap.Message{Message: "two"})
+		assert.True(t, f.UsingSecondary())
+		assert.Len(t, reported, 1)
+
+		failing = false
+		impl(ctx, logwrap.Message{Message: "three"})
+
+		assert.Empty(t, primary.Messages())
+		assert.Len(t, secondary.Messages(), 3)
+
+		time.Sleep(25 * time.Millisecond)
+
+		impl(ctx, logwrap.Message{Message: "four"})
+		assert.False(t, f.UsingSecondary())
+		assert.Len(t, primary.Messages(), 1)
+		assert.Len(t, reported, 2)
+	})
+	t.Run("failures reported after the primary returns count towards switching", func(t *testing.T) {
+		primary := capture.NewCapture()
+		secondary := capture.NewCapture()
+		failing := true
+
+		a := async.NewAsync(failingImpl(primary, &failing), async.ReportInterval(0))
+		defer a.Close(context.Background())
+
+		f := NewFallback(a.Impl(), secondary.Impl(), FailureThreshold(2))
+		impl := f.Impl()
+
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(error) {})
+
+		impl(ctx, logwrap.Message{Level: logwrap.Info, Message: "one"})
+		impl(ctx, logwrap.Message{Level: logwrap.Info, Message: "two"})
+		assert.NoError(t, a.Flush(context.Background()))
+
+		assert.True(t, f.UsingSecondary())
+
+		impl(ctx, logwrap.Message{Level: logwrap.Info, Message: "three"})
+		assert.Len(t, secondary.Messages(), 1)
+	})
+}
@@ -0,0 +1,612 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseError is returned when a filter expression can not be compiled, it describes the problem and the position in
+// the expression at which it was found.
+type ParseError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+// Error renders the problem with the position and a snippet of the expression it was found at.
+func (e ParseError) Error() string {
+	return fmt.Sprintf("filter: %s at position %d: %q", e.Message, e.Position, snippet(e.Expression, e.Position))
+}
+
+func snippet(expression string, position int) string {
+	if position >= len(expression) {
+		return "<end>"
+	}
+
+	rest := expression[position:]
+	if len(rest) > 20 {
+		cut := 20
+		for cut > 0 && !utf8.RuneStart(rest[cut]) {
+			cut--
+		}
+		rest = rest[:cut] + "..."
+	}
+
+	return rest
+}
+
+// Expression compiles a filter expression and returns an implementation which only passes matching messages to impl,
+// as with Filter.
+func Expression(impl logwrap.Impl, expression string) (logwrap.Impl, error) {
+	filter, err := Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return Filter(impl, filter), nil
+}
+
+// MustCompile is as Compile but panics if the expression can not be compiled.
+func MustCompile(expression string) func(message logwrap.Message) bool {
+	filter, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+
+	return filter
+}
+
+// Compile compiles a filter expression into a function suitable for Filter, allowing filters to be provided by
+// configuration. Errors are returned as ParseError.
+//
+// An expression is made of comparisons combined with &&, || and !, and grouped with parentheses, for example:
+//
+//	level <= warn && source =~ "zigbee\..*" && data.ieee == "00124b0012345678"
+//
+// Strings are double quoted and may contain Go escape sequences, a backslash before any other character is kept, so
+// regular expressions need not escape their backslashes.
+//
+// The fields which may be compared are:
+//
+// * level - compared with a level name (panic, fatal, error, warn, info, debug, trace) or number using ==, !=, <, <=,
+// > and >=. Levels compare as they do in logwrap, more severe levels are lesser, so `level <= warn` matches Warn and
+// more severe messages.
+// * source and message - compared with a string using == and !=, or matched against a regular expression with =~ and
+// !~.
+// * data.<name> - compared with a string, number or true/false. Strings compare with the value as rendered by
+// format.String, numbers compare numerically, and regular expressions are matched against the rendered value. A data
+// field on its own, such as `data.ieee`, tests for the fields presence. Comparisons against absent fields are false.
+func Compile(expression string) (func(message logwrap.Message) bool, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{expression: expression, tokens: tokens}
+
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.errorf(t, "unexpected %s", t.describe())
+	}
+
+	return predicate, nil
+}
+
+type predicate func(message logwrap.Message) bool
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	unquoted string
+	position int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of expression"
+	case tokenString:
+		return "string " + t.text
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">"}
+
+// lex splits the expression into tokens.
+func lex(expression string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expression); {
+		c, width := utf8.DecodeRuneInString(expression[i:])
+
+		switch {
+		case unicode.IsSpace(c):
+			i += width
+		case strings.HasPrefix(expression[i:], "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, text: "&&", position: i})
+			i += 2
+		case strings.HasPrefix(expression[i:], "||"):
+			tokens = append(tokens, token{kind: tokenOr, text: "||", position: i})
+			i += 2
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", position: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expression) && expression[end] != '"' {
+				if expression[end] == '\\' {
+					end++
+				}
+				end++
+			}
+
+			if end >= len(expression) {
+				return nil, ParseError{Expression: expression, Position: i, Message: "unterminated string"}
+			}
+
+			text := expression[i : end+1]
+			tokens = append(tokens, token{kind: tokenString, text: text, unquoted: unquote(text), position: i})
+			i = end + 1
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			end := i + 1
+			for end < len(expression) && (expression[end] == '.' || unicode.IsDigit(rune(expression[end]))) {
+				end++
+			}
+
+			if _, err := strconv.ParseFloat(expression[i:end], 64); err != nil {
+				return nil, ParseError{Expression: expression, Position: i, Message: "invalid number"}
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:end], position: i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i + width
+			for end < len(expression) {
+				next, nextWidth := utf8.DecodeRuneInString(expression[end:])
+				if !isIdentifier(next) {
+					break
+				}
+				end += nextWidth
+			}
+
+			tokens = append(tokens, token{kind: tokenIdentifier, text: expression[i:end], position: i})
+			i = end
+		default:
+			matched := false
+
+			for _, operator := range operators {
+				if strings.HasPrefix(expression[i:], operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, position: i})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+
+			if !matched && c == '!' {
+				tokens = append(tokens, token{kind: tokenNot, text: "!", position: i})
+				i++
+				matched = true
+			}
+
+			if !matched {
+				return nil, ParseError{Expression: expression, Position: i, Message: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, position: len(expression)}), nil
+}
+
+// unquote removes the quotes from a lexed string and decodes Go escape sequences, unknown escapes such as those in
+// regular expressions are kept literally, so "zigbee\..*" and "zigbee\\..*" are equivalent.
+func unquote(text string) string {
+	remaining := text[1 : len(text)-1]
+
+	var unquoted strings.Builder
+
+	for len(remaining) > 0 {
+		value, multibyte, tail, err := strconv.UnquoteChar(remaining, '"')
+		if err != nil {
+			unquoted.WriteByte(remaining[0])
+			remaining = remaining[1:]
+			continue
+		}
+
+		if value < utf8.RuneSelf || !multibyte {
+			unquoted.WriteByte(byte(value))
+		} else {
+			unquoted.WriteRune(value)
+		}
+
+		remaining = tail
+	}
+
+	return unquoted.String()
+}
+
+func isIdentifier(c rune) bool {
+	return c == '_' || c == '.' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	position   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEnd {
+		p.position++
+	}
+
+	return t
+}
+
+func (p *parser) errorf(t token, message string, args ...interface{}) error {
+	return ParseError{Expression: p.expression, Position: t.position, Message: fmt.Sprintf(message, args...)}
+}
+
+func (p *parser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(message logwrap.Message) bool {
+			return l(message) || right(message)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(message logwrap.Message) bool {
+			return l(message) && right(message)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (predicate, error) {
+	switch t := p.peek(); t.kind {
+	case tokenNot:
+		p.next()
+
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(message logwrap.Message) bool {
+			return !inner(message)
+		}, nil
+	case tokenOpen:
+		p.next()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokenClose {
+			return nil, p.errorf(closing, "expected \")\" but found %s", closing.describe())
+		}
+
+		return inner, nil
+	case tokenIdentifier:
+		return p.parseComparison()
+	default:
+		return nil, p.errorf(t, "expected a field, \"!\" or \"(\" but found %s", t.describe())
+	}
+}
+
+func (p *parser) parseComparison() (predicate, error) {
+	field := p.next()
+
+	var fieldName string
+	isData := strings.HasPrefix(field.text, "data.")
+
+	if isData {
+		fieldName = strings.TrimPrefix(field.text, "data.")
+		if fieldName == "" {
+			return nil, p.errorf(field, "expected a field name after \"data.\"")
+		}
+	} else if field.text != "level" && field.text != "source" && field.text != "message" {
+		return nil, p.errorf(field, "unknown field %q, expected level, source, message or data.<name>", field.text)
+	}
+
+	operator := p.peek()
+	if operator.kind != tokenOperator {
+		if isData {
+			return func(message logwrap.Message) bool {
+				_, found := message.Data[fieldName]
+				return found
+			}, nil
+		}
+
+		return nil, p.errorf(operator, "expected a comparison operator after %q but found %s", field.text, operator.describe())
+	}
+	p.next()
+
+	value := p.next()
+	if value.kind != tokenString && value.kind != tokenNumber && value.kind != tokenIdentifier {
+		return nil, p.errorf(value, "expected a value after %q but found %s", operator.text, value.describe())
+	}
+
+	switch field.text {
+	case "level":
+		return p.compileLevel(operator, value)
+	case "source":
+		return p.compileString(operator, value, func(message logwrap.Message) string { return message.Source })
+	case "message":
+		return p.compileString(operator, value, func(message logwrap.Message) string { return message.Message })
+	default:
+		return p.compileData(fieldName, operator, value)
+	}
+}
+
+func (p *parser) compileLevel(operator token, value token) (predicate, error) {
+	var level logwrap.LogLevel
+
+	switch value.kind {
+	case tokenIdentifier:
+		found := false
+
+		for l := logwrap.Panic; l <= logwrap.Trace; l++ {
+			if strings.EqualFold(l.String(), value.text) {
+				level = l
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, p.errorf(value, "unknown level %q", value.text)
+		}
+	case tokenNumber:
+		n, err := strconv.ParseUint(value.text, 10, 32)
+		if err != nil {
+			return nil, p.errorf(value, "invalid level %q", value.text)
+		}
+
+		level = logwrap.LogLevel(n)
+	default:
+		return nil, p.errorf(value, "expected a level name or number but found %s", value.describe())
+	}
+
+	compare, err := p.ordering(operator, "level")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(message logwrap.Message) bool {
+		return compare(compareUint(uint64(message.Level), uint64(level)))
+	}, nil
+}
+
+func (p *parser) compileString(operator token, value token, get func(logwrap.Message) string) (predicate, error) {
+	if value.kind != tokenString {
+		return nil, p.errorf(value, "expected a string but found %s", value.describe())
+	}
+
+	text := value.unquoted
+
+	switch operator.text {
+	case "==":
+		return func(message logwrap.Message) bool { return get(message) == text }, nil
+	case "!=":
+		return func(message logwrap.Message) bool { return get(message) != text }, nil
+	case "=~", "!~":
+		re, err := p.compileRegexp(value, text)
+		if err != nil {
+			return nil, err
+		}
+
+		negate := operator.text == "!~"
+		return func(message logwrap.Message) bool { return re.MatchString(get(message)) != negate }, nil
+	default:
+		return nil, p.errorf(operator, "operator %q is not supported for strings, expected ==, !=, =~ or !~", operator.text)
+	}
+}
+
+func (p *parser) compileData(field string, operator token, value token) (predicate, error) {
+	lookup := func(message logwrap.Message) (interface{}, bool) {
+		v, found := message.Data[field]
+		return v, found
+	}
+
+	switch value.kind {
+	case tokenString:
+		text := value.unquoted
+
+		if operator.text == "=~" || operator.text == "!~" {
+			re, err := p.compileRegexp(value, text)
+			if err != nil {
+				return nil, err
+			}
+
+			negate := operator.text == "!~"
+			return func(message logwrap.Message) bool {
+				v, found := lookup(message)
+				return found && re.MatchString(format.String(v, time.RFC3339Nano)) != negate
+			}, nil
+		}
+
+		compare, err := p.ordering(operator, "strings")
+		if err != nil {
+			return nil, err
+		}
+
+		return func(message logwrap.Message) bool {
+			v, found := lookup(message)
+			return found && compare(strings.Compare(format.String(v, time.RFC3339Nano), text))
+		}, nil
+	case tokenNumber:
+		number, _ := strconv.ParseFloat(value.text, 64)
+
+		compare, err := p.ordering(operator, "numbers")
+		if err != nil {
+			return nil, err
+		}
+
+		return func(message logwrap.Message) bool {
+			v, found := lookup(message)
+			if !found {
+				return false
+			}
+
+			n, ok := toFloat(v)
+			return ok && compare(compareFloat(n, number))
+		}, nil
+	default:
+		if value.text != "true" && value.text != "false" {
+			return nil, p.errorf(value, "expected a string, number, true or false but found %s", value.describe())
+		}
+
+		if operator.text != "==" && operator.text != "!=" {
+			return nil, p.errorf(operator, "operator %q is not supported for booleans, expected == or !=", operator.text)
+		}
+
+		expected := value.text == "true"
+		negate := operator.text == "!="
+
+		return func(message logwrap.Message) bool {
+			v, found := lookup(message)
+			if !found {
+				return false
+			}
+
+			b, ok := v.(bool)
+			return ok && (b == expected) != negate
+		}, nil
+	}
+}
+
+func (p *parser) compileRegexp(value token, text string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(text)
+	if err != nil {
+		return nil, p.errorf(value, "invalid regular expression: %v", err)
+	}
+
+	return re, nil
+}
+
+// ordering returns a function which interprets the result of a three way comparison according to the operator.
+func (p *parser) ordering(operator token, kind string) (func(int) bool, error) {
+	switch operator.text {
+	case "==":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	default:
+		return nil, p.errorf(operator, "operator %q is not supported for %s, expected ==, !=, <, <=, > or >=", operator.text, kind)
+	}
+}
+
+func compareUint(a uint64, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// toFloat converts numeric data values to a float64 for comparison.
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
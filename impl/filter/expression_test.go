@@ -0,0 +1,172 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	message := logwrap.Message{
+		Level:   logwrap.Warn,
+		Source:  "zigbee.network",
+		Message: "device unresponsive",
+		Data: map[string]interface{}{
+			"ieee":    "00124b0012345678",
+			"retries": 3,
+			"online":  false,
+			"err":     errors.New("timeout"),
+			"größe":   1,
+		},
+	}
+
+	matching := []string{
+		`level <= warn`,
+		`level == WARN`,
+		`level > error`,
+		`level == 3`,
+		`source == "zigbee.network"`,
+		`source =~ "zigbee\\..*"`,
+		`source =~ "^zigbee\.network$"`,
+		`message == "device\x20unresponsive"`,
+		`message !~ "^device online"`,
+		`data.ieee == "00124b0012345678"`,
+		`data.ieee =~ "^00124b"`,
+		`data.retries >= 3`,
+		`data.größe == 1`,
+		`data.retries < 3.5`,
+		`data.online == false`,
+		`data.err == "timeout"`,
+		`data.ieee`,
+		`!data.missing`,
+		`level <= warn && source =~ "zigbee\\..*" && data.ieee == "00124b0012345678"`,
+		`level == error || source == "zigbee.network"`,
+		`!(level == error || level == info)`,
+		`level == info || level == warn && data.online != true`,
+	}
+
+	for _, expression := range matching {
+		filter, err := Compile(expression)
+		if assert.NoError(t, err, expression) {
+			assert.True(t, filter(message), expression)
+		}
+	}
+
+	notMatching := []string{
+		`level < warn`,
+		`level >= info`,
+		`source != "zigbee.network"`,
+		`message =~ "online"`,
+		`data.ieee == "other"`,
+		`data.retries > 3`,
+		`data.ieee > 5`,
+		`data.missing == "value"`,
+		`data.missing != "value"`,
+		`data.missing`,
+		`(level == info || level == error) && data.ieee`,
+	}
+
+	for _, expression := range notMatching {
+		filter, err := Compile(expression)
+		if assert.NoError(t, err, expression) {
+			assert.False(t, filter(message), expression)
+		}
+	}
+
+	t.Run("unknown escapes in strings are kept literally", func(t *testing.T) {
+		filter, err := Compile(`level <= warn && source =~ "zigbee\..*" && data.ieee == "00124b..."`)
+		if assert.NoError(t, err) {
+			assert.True(t, filter(logwrap.Message{Level: logwrap.Warn, Source: "zigbee.network", Data: map[string]interface{}{"ieee": "00124b..."}}))
+			assert.False(t, filter(logwrap.Message{Level: logwrap.Warn, Source: "zigbeexnetwork", Data: map[string]interface{}{"ieee": "00124b..."}}))
+		}
+
+		filter, err = Compile(`message == "bad \q escape"`)
+		if assert.NoError(t, err) {
+			assert.True(t, filter(logwrap.Message{Message: `bad \q escape`}))
+		}
+	})
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := map[string]struct {
+		position int
+		message  string
+	}{
+		``:                           {0, `expected a field, "!" or "(" but found end of expression`},
+		`level <=`:                   {8, `expected a value after "<=" but found end of expression`},
+		`lvl == warn`:                {0, `unknown field "lvl", expected level, source, message or data.<name>`},
+		`level == loud`:              {9, `unknown level "loud"`},
+		`level =~ "warn"`:            {9, `expected a level name or number but found string "warn"`},
+		`source < "a"`:               {7, `operator "<" is not supported for strings, expected ==, !=, =~ or !~`},
+		`source == zigbee`:           {10, `expected a string but found "zigbee"`},
+		`source =~ "("`:              {10, "invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		`source == "unterminated`:    {10, `unterminated string`},
+		`(level == warn`:             {14, `expected ")" but found end of expression`},
+		`level == warn warn`:         {14, `unexpected "warn"`},
+		`level == warn & source`:     {14, `unexpected character '&'`},
+		`source`:                     {6, `expected a comparison operator after "source" but found end of expression`},
+		`data.online > true`:         {12, `operator ">" is not supported for booleans, expected == or !=`},
+		`data.retries =~ 5`:          {13, `operator "=~" is not supported for numbers, expected ==, !=, <, <=, > or >=`},
+		`data. == 1`:                 {0, `expected a field name after "data."`},
+		`level == warn && || source`: {17, `expected a field, "!" or "(" but found "||"`},
+		`data.value == maybe`:        {14, `expected a string, number, true or false but found "maybe"`},
+		`data.value == 1.2.3`:        {14, `invalid number`},
+		`level == warn || source ==`: {26, `expected a value after "==" but found end of expression`},
+		`level == warn § source`:     {14, `unexpected character '§'`},
+	}
+
+	for expression, expected := range tests {
+		_, err := Compile(expression)
+
+		var parseError ParseError
+		if assert.True(t, errors.As(err, &parseError), expression) {
+			assert.Equal(t, expected.position, parseError.Position, expression)
+			assert.Equal(t, expected.message, parseError.Message, expression)
+			assert.Equal(t, expression, parseError.Expression)
+		}
+	}
+
+	t.Run("errors describe the position and the expression from that point", func(t *testing.T) {
+		_, err := Compile(`level == warn && source =~ "("`)
+		assert.EqualError(t, err, "filter: invalid regular expression: error parsing regexp: missing closing ): `(` at position 27: \"\\\"(\\\"\"")
+	})
+
+	t.Run("snippets of non-ASCII expressions are not cut within a character", func(t *testing.T) {
+		_, err := Compile(`data.größe == größere größe`)
+		assert.EqualError(t, err, `filter: expected a string, number, true or false but found "größere" at position 16: "größere größe"`)
+
+		_, err = Compile(`level == warn § ääääääääääääääää`)
+		assert.EqualError(t, err, `filter: unexpected character '§' at position 14: "§ ääääääää..."`)
+	})
+}
+
+func TestMustCompile(t *testing.T) {
+	t.Run("panics if the expression is invalid", func(t *testing.T) {
+		assert.Panics(t, func() {
+			MustCompile("level ==")
+		})
+	})
+}
+
+func TestExpression(t *testing.T) {
+	t.Run("filters messages with the compiled expression", func(t *testing.T) {
+		mockImplOne := MockImpl{}
+		mockImplOne.On("Impl", mock.Anything, mock.Anything).Once()
+
+		impl, err := Expression(mockImplOne.Impl, `level <= warn`)
+		assert.NoError(t, err)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info})
+
+		mockImplOne.AssertExpectations(t)
+	})
+
+	t.Run("returns an error if the expression is invalid", func(t *testing.T) {
+		_, err := Expression(nil, `level`)
+		assert.Error(t, err)
+	})
+}
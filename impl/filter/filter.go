@@ -13,3 +13,8 @@ func Filter(impl logwrap.Impl, filter func(message logwrap.Message) bool) logwra
 		}
 	}
 }
+
+// FilterSink is a Sink variant of Filter, flushing or closing it flushes or closes the destination.
+func FilterSink(dest logwrap.Sink, filter func(message logwrap.Message) bool) logwrap.Sink {
+	return logwrap.WithLifecycle(Filter(dest.Impl(), filter), dest.Flush, dest.Close)
+}
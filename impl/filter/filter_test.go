@@ -36,3 +36,33 @@ func TestFilter(t *testing.T) {
 		assert.Equal(t, matchingMessage.Message, capturedMessage.Message)
 	})
 }
+
+func TestFilterSink(t *testing.T) {
+	t.Run("filter sink filters messages and propagates flush and close to the destination", func(t *testing.T) {
+		mockImplOne := MockImpl{}
+		mockImplOne.On("Impl", mock.Anything, mock.Anything).Once()
+
+		flushed := false
+		closed := false
+
+		sink := FilterSink(logwrap.WithLifecycle(mockImplOne.Impl, func(context.Context) error {
+			flushed = true
+			return nil
+		}, func(context.Context) error {
+			closed = true
+			return nil
+		}), func(message logwrap.Message) bool {
+			return message.Message == "match"
+		})
+
+		sink.Impl()(context.Background(), logwrap.Message{Message: "match"})
+		sink.Impl()(context.Background(), logwrap.Message{Message: "nomatch"})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.NoError(t, sink.Close(context.Background()))
+
+		assert.True(t, mockImplOne.AssertExpectations(t))
+		assert.True(t, flushed)
+		assert.True(t, closed)
+	})
+}
@@ -0,0 +1,226 @@
+package fingerscrossed
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+)
+
+// Option is a configuration option for the fingers crossed implementation.
+type Option func(*FingersCrossed)
+
+// DefaultBufferLevel is the default level at or below which messages within a segment are buffered.
+const DefaultBufferLevel = logwrap.Debug
+
+// DefaultTriggerLevel is the default level at or above which a message causes buffered messages to be emitted.
+const DefaultTriggerLevel = logwrap.Error
+
+// DefaultBufferSize is the default number of messages buffered for each root segment.
+const DefaultBufferSize = 1000
+
+// DefaultMaximumSegments is the default number of root segments which may be buffered at once.
+const DefaultMaximumSegments = 1000
+
+// BufferLevel sets the level at or below which messages within a segment are buffered, by default Debug, so that Debug
+// and Trace messages are buffered.
+func BufferLevel(level logwrap.LogLevel) Option {
+	return func(f *FingersCrossed) {
+		f.bufferLevel = level
+	}
+}
+
+// TriggerLevel sets the level at or above which a message within a segment causes the buffered messages to be emitted,
+// by default Error.
+func TriggerLevel(level logwrap.LogLevel) Option {
+	return func(f *FingersCrossed) {
+		f.triggerLevel = level
+	}
+}
+
+// BufferSize sets the number of messages buffered for each root segment, once full the oldest messages are discarded.
+func BufferSize(size int) Option {
+	return func(f *FingersCrossed) {
+		f.bufferSize = size
+	}
+}
+
+// MaximumSegments sets the number of root segments which may be buffered at once, verbose messages from further
+// segments are discarded until others end.
+func MaximumSegments(count int) Option {
+	return func(f *FingersCrossed) {
+		f.maximumSegments = count
+	}
+}
+
+// NewFingersCrossedSink initialises a new FingersCrossed implementation as with NewFingersCrossed, flushing or closing
+// it also flushes or closes the destination.
+func NewFingersCrossedSink(dest logwrap.Sink, options ...Option) *FingersCrossed {
+	f := NewFingersCrossed(dest.Impl(), options...)
+	f.destLifecycle = dest
+
+	return f
+}
+
+// NewFingersCrossed initialises a new FingersCrossed implementation which buffers verbose messages logged within a
+// Segment, only sending them to the destination if the segment fails, the handle to be provided to logwrap should be
+// obtained by calling Impl().
+//
+// Messages are grouped by their root segment using the segmentID and parentSegmentID fields. Verbose messages are
+// buffered until a message at or above the TriggerLevel is logged within the segment tree, at which point the buffer is
+// sent to the destination and further verbose messages in the tree are passed straight through. If the root segment ends
+// without being triggered, the buffer is discarded. Messages which are not buffered are passed through immediately, so
+// buffered messages are emitted after them and should be ordered by their sequence or timestamp.
+func NewFingersCrossed(dest logwrap.Impl, options ...Option) *FingersCrossed {
+	f := &FingersCrossed{
+		dest:            dest,
+		bufferLevel:     DefaultBufferLevel,
+		triggerLevel:    DefaultTriggerLevel,
+		bufferSize:      DefaultBufferSize,
+		maximumSegments: DefaultMaximumSegments,
+		mutex:           &sync.Mutex{},
+		roots:           map[uint64]uint64{},
+		buffers:         map[uint64]*buffer{},
+	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	return f
+}
+
+// FingersCrossed is a structure which provides a log implementation that buffers verbose messages within segments.
+type FingersCrossed struct {
+	dest            logwrap.Impl
+	destLifecycle   logwrap.Lifecycle
+	bufferLevel     logwrap.LogLevel
+	triggerLevel    logwrap.LogLevel
+	bufferSize      int
+	maximumSegments int
+
+	mutex   *sync.Mutex
+	roots   map[uint64]uint64
+	buffers map[uint64]*buffer
+}
+
+type buffer struct {
+	triggered bool
+	entries   []entry
+}
+
+type entry struct {
+	ctx     context.Context
+	message logwrap.Message
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (f *FingersCrossed) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		segmentID, ok := message.Data[logwrap.SegmentIDField].(uint64)
+		if !ok {
+			f.dest(ctx, message)
+			return
+		}
+
+		f.mutex.Lock()
+
+		root := f.root(segmentID, message)
+		buf := f.buffers[root]
+
+		if buf == nil && len(f.buffers) < f.maximumSegments {
+			buf = &buffer{}
+			f.buffers[root] = buf
+		}
+
+		var emit []entry
+
+		switch {
+		case buf != nil && message.Level <= f.triggerLevel && !buf.triggered:
+			buf.triggered = true
+			emit = buf.entries
+			buf.entries = nil
+		case message.Level >= f.bufferLevel && (buf == nil || !buf.triggered):
+			if buf != nil && f.bufferSize > 0 {
+				if len(buf.entries) >= f.bufferSize {
+					buf.entries = buf.entries[1:]
+				}
+
+				buf.entries = append(buf.entries, entry{ctx: ctx, message: message.Clone()})
+			}
+
+			f.endSegment(segmentID, root, message)
+			f.mutex.Unlock()
+			return
+		}
+
+		f.endSegment(segmentID, root, message)
+		f.mutex.Unlock()
+
+		for _, e := range emit {
+			f.dest(e.ctx, e.message)
+		}
+
+		f.dest(ctx, message)
+	}
+}
+
+// Flush flushes the destination, buffered messages are retained.
+func (f *FingersCrossed) Flush(ctx context.Context) error {
+	if f.destLifecycle != nil {
+		return f.destLifecycle.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Close discards all buffered messages and closes the destination. FingersCrossed satisfies logwrap.Sink.
+func (f *FingersCrossed) Close(ctx context.Context) error {
+	f.mutex.Lock()
+	f.roots = map[uint64]uint64{}
+	f.buffers = map[uint64]*buffer{}
+	f.mutex.Unlock()
+
+	if f.destLifecycle != nil {
+		return f.destLifecycle.Close(ctx)
+	}
+
+	return nil
+}
+
+// root determines the root segment of a message, tracking the root of each open segment. Must be called with the mutex
+// held.
+func (f *FingersCrossed) root(segmentID uint64, message logwrap.Message) uint64 {
+	if root, found := f.roots[segmentID]; found {
+		return root
+	}
+
+	root := segmentID
+
+	if parentID, ok := message.Data[logwrap.ParentSegmentIDField].(uint64); ok {
+		if parentRoot, found := f.roots[parentID]; found {
+			root = parentRoot
+		} else {
+			root = parentID
+		}
+	}
+
+	if message.Data[logwrap.SegmentField] == logwrap.SegmentStartValue {
+		f.roots[segmentID] = root
+	}
+
+	return root
+}
+
+// endSegment stops tracking a segment if the message ends it, discarding the buffer if it is the root segment. Must be
+// called with the mutex held.
+func (f *FingersCrossed) endSegment(segmentID uint64, root uint64, message logwrap.Message) {
+	if message.Data[logwrap.SegmentField] != logwrap.SegmentEndValue {
+		return
+	}
+
+	delete(f.roots, segmentID)
+
+	if segmentID == root {
+		delete(f.buffers, root)
+	}
+}
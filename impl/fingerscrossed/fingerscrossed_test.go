@@ -0,0 +1,119 @@
+package fingerscrossed
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func messageTexts(messages []logwrap.Message) []string {
+	var texts []string
+
+	for _, message := range messages {
+		texts = append(texts, message.Message)
+	}
+
+	return texts
+}
+
+func TestFingersCrossed(t *testing.T) {
+	t.Run("verbose messages in a successful segment are discarded", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl()).Impl())
+
+		ctx, end := logger.Segment(context.Background(), "interview")
+		logger.Debug(ctx, "detail")
+		logger.Trace(ctx, "more detail")
+		logger.Info(ctx, "progress")
+		end()
+
+		assert.Equal(t, []string{"interview", "progress", "interview"}, messageTexts(c.Messages()))
+	})
+
+	t.Run("verbose messages are emitted when an error is logged within the segment tree", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl()).Impl())
+
+		ctx, end := logger.Segment(context.Background(), "interview")
+		logger.Debug(ctx, "outer detail")
+
+		subCtx, subEnd := logger.Segment(ctx, "step", logwrap.Level(logwrap.Debug))
+		logger.Debug(subCtx, "inner detail")
+		logger.Error(subCtx, "failure")
+		logger.Debug(subCtx, "after failure")
+		subEnd()
+		end()
+
+		expected := []string{"interview", "outer detail", "step", "inner detail", "failure", "after failure", "step", "interview"}
+		assert.Equal(t, expected, messageTexts(c.Messages()))
+	})
+
+	t.Run("messages outside of segments are passed through", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl()).Impl())
+
+		logger.Trace(context.Background(), "message")
+
+		assert.Len(t, c.Messages(), 1)
+	})
+
+	t.Run("separate segment trees are buffered independently", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl()).Impl())
+
+		ctxOne, endOne := logger.Segment(context.Background(), "one", logwrap.Level(logwrap.Debug))
+		ctxTwo, endTwo := logger.Segment(context.Background(), "two", logwrap.Level(logwrap.Debug))
+		logger.Debug(ctxOne, "one detail")
+		logger.Debug(ctxTwo, "two detail")
+		logger.Error(ctxTwo, "two failure")
+		endOne()
+		endTwo()
+
+		assert.Equal(t, []string{"two", "two detail", "two failure", "two"}, messageTexts(c.Messages()))
+	})
+
+	t.Run("buffers are capped by discarding the oldest messages", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl(), BufferSize(2)).Impl())
+
+		ctx, end := logger.Segment(context.Background(), "interview")
+		logger.Debug(ctx, "one")
+		logger.Debug(ctx, "two")
+		logger.Debug(ctx, "three")
+		logger.Error(ctx, "failure")
+		end()
+
+		assert.Equal(t, []string{"interview", "two", "three", "failure", "interview"}, messageTexts(c.Messages()))
+	})
+
+	t.Run("segments beyond the maximum are not buffered", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl(), MaximumSegments(1)).Impl())
+
+		ctxOne, endOne := logger.Segment(context.Background(), "one")
+		defer endOne()
+		ctxTwo, endTwo := logger.Segment(context.Background(), "two")
+		defer endTwo()
+
+		logger.Debug(ctxOne, "one detail")
+		logger.Debug(ctxTwo, "two detail")
+		logger.Error(ctxOne, "one failure")
+		logger.Error(ctxTwo, "two failure")
+
+		assert.Equal(t, []string{"one", "two", "one detail", "one failure", "two failure"}, messageTexts(c.Messages()))
+	})
+
+	t.Run("trigger and buffer levels are configurable", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(NewFingersCrossed(c.Impl(), BufferLevel(logwrap.Info), TriggerLevel(logwrap.Warn)).Impl())
+
+		ctx, end := logger.Segment(context.Background(), "interview", logwrap.Level(logwrap.Warn))
+		logger.Info(ctx, "detail")
+		logger.Warn(ctx, "warning")
+		end()
+
+		assert.Equal(t, []string{"interview", "detail", "warning", "interview"}, messageTexts(c.Messages()))
+	})
+}
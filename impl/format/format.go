@@ -0,0 +1,122 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Formatter renders a message into the bytes to be written by a sink, such as a single line of text.
+type Formatter interface {
+	Format(logwrap.Message) ([]byte, error)
+}
+
+// FormatterFunc is an adapter to allow the use of ordinary functions as a Formatter.
+type FormatterFunc func(logwrap.Message) ([]byte, error)
+
+// Format calls f(message).
+func (f FormatterFunc) Format(message logwrap.Message) ([]byte, error) {
+	return f(message)
+}
+
+// Value normalises a data value for structured encoders such as JSON, so that all formatters render values in the same
+// way. Errors, durations, byte slices, fmt.Stringer and logwrap.SourceLocation are converted to strings, all other
+// values are returned unchanged. Nil pointers are returned as nil, rather than calling their methods.
+func Value(value interface{}) interface{} {
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case nil, string, bool, time.Time:
+		return v
+	case []byte:
+		return string(v)
+	case logwrap.SourceLocation:
+		return fmt.Sprintf("%s:%d", v.File, v.Line)
+	case error:
+		if _, isMarshaler := value.(json.Marshaler); isMarshaler {
+			return v
+		}
+		return v.Error()
+	case fmt.Stringer:
+		if _, isMarshaler := value.(json.Marshaler); isMarshaler {
+			return v
+		}
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// String renders a data value as text for unstructured formatters. Values are converted as with Value, time values are
+// rendered with the layout provided, and nested values such as maps, slices and structs are rendered deterministically
+// as JSON where possible, falling back to fmt.
+func String(value interface{}, timeLayout string) string {
+	switch v := Value(value).(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case time.Time:
+		return v.Format(timeLayout)
+	default:
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+			if data, err := json.Marshal(v); err == nil {
+				return string(data)
+			}
+		}
+
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+// JSONValue marshals a data value into JSON after normalising it with Value, should the value fail to marshal it is
+// rendered as a JSON string using String instead.
+func JSONValue(value interface{}) []byte {
+	value = Value(value)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(String(value, time.RFC3339Nano))
+	}
+
+	return data
+}
+
+// JSONData marshals a messages data into a JSON object in key order. Each value is marshalled with JSONValue, so a value
+// which can not be marshalled does not cause the loss of other fields.
+func JSONData(data map[string]interface{}) []byte {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+
+	for i, key := range SortedKeys(data) {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.Write(JSONValue(key))
+		buffer.WriteByte(':')
+		buffer.Write(JSONValue(data[key]))
+	}
+
+	buffer.WriteByte('}')
+	return buffer.Bytes()
+}
+
+// SortedKeys returns the keys of a messages data in order, for formatters to render data deterministically.
+func SortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type stringer struct{}
+
+func (s stringer) String() string {
+	return "stringer"
+}
+
+type pointerError struct {
+	message string
+}
+
+func (e *pointerError) Error() string {
+	return e.message
+}
+
+func TestValue(t *testing.T) {
+	t.Run("normalises errors, durations, byte slices, stringers and source locations to strings", func(t *testing.T) {
+		assert.Equal(t, "failure", Value(errors.New("failure")))
+		assert.Equal(t, "1.5s", Value(1500*time.Millisecond))
+		assert.Equal(t, "bytes", Value([]byte("bytes")))
+		assert.Equal(t, "stringer", Value(stringer{}))
+		assert.Equal(t, "/src/main.go:42", Value(logwrap.SourceLocation{Function: "main.main", File: "/src/main.go", Line: 42}))
+	})
+
+	t.Run("leaves other values unchanged", func(t *testing.T) {
+		now := time.Now()
+
+		assert.Equal(t, 1, Value(1))
+		assert.Equal(t, true, Value(true))
+		assert.Equal(t, now, Value(now))
+		assert.Equal(t, map[string]int{"a": 1}, Value(map[string]int{"a": 1}))
+		assert.Nil(t, Value(nil))
+	})
+
+	t.Run("nil pointers are returned as nil without calling their methods", func(t *testing.T) {
+		assert.Nil(t, Value((*pointerError)(nil)))
+		assert.Nil(t, Value((*stringer)(nil)))
+		assert.Equal(t, "null", String((*pointerError)(nil), time.RFC3339))
+		assert.Equal(t, `{"e":null}`, string(JSONData(map[string]interface{}{"e": (*pointerError)(nil)})))
+	})
+}
+
+func TestString(t *testing.T) {
+	t.Run("renders scalar values as text", func(t *testing.T) {
+		assert.Equal(t, "value", String("value", time.RFC3339))
+		assert.Equal(t, "1", String(1, time.RFC3339))
+		assert.Equal(t, "null", String(nil, time.RFC3339))
+		assert.Equal(t, "failure", String(errors.New("failure"), time.RFC3339))
+	})
+
+	t.Run("renders time values with the layout provided", func(t *testing.T) {
+		when := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		assert.Equal(t, "2020-06-01", String(when, "2006-01-02"))
+	})
+
+	t.Run("renders nested values deterministically as json, falling back to fmt", func(t *testing.T) {
+		assert.Equal(t, `{"a":2,"z":1}`, String(map[string]int{"z": 1, "a": 2}, time.RFC3339))
+		assert.Equal(t, `["a","b"]`, String([]string{"a", "b"}, time.RFC3339))
+
+		channel := make(chan bool)
+		assert.Equal(t, fmt.Sprintf("%+v", channel), String(channel, time.RFC3339))
+	})
+}
+
+func TestSortedKeys(t *testing.T) {
+	t.Run("returns data keys in order", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c"}, SortedKeys(map[string]interface{}{"c": 1, "a": 2, "b": 3}))
+	})
+}
+
+func TestJSONValue(t *testing.T) {
+	t.Run("marshals normalised values into json", func(t *testing.T) {
+		assert.Equal(t, `"failure"`, string(JSONValue(errors.New("failure"))))
+		assert.Equal(t, `{"a":1}`, string(JSONValue(map[string]int{"a": 1})))
+	})
+
+	t.Run("renders values which fail to marshal as json strings", func(t *testing.T) {
+		channel := make(chan bool)
+		assert.Equal(t, fmt.Sprintf("%q", fmt.Sprintf("%+v", channel)), string(JSONValue(channel)))
+	})
+}
+
+func TestJSONData(t *testing.T) {
+	t.Run("marshals data in key order without losing fields which fail to marshal", func(t *testing.T) {
+		channel := make(chan bool)
+
+		data := JSONData(map[string]interface{}{"b": "value", "a": channel})
+
+		assert.Equal(t, fmt.Sprintf(`{"a":%q,"b":"value"}`, fmt.Sprintf("%+v", channel)), string(data))
+	})
+}
@@ -2,33 +2,102 @@ package golog
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Option is a configuration option for the log/Logger wrapper.
+type Option func(*config)
+
+type config struct {
+	layout           string
+	timeFormat       string
+	delegateTerminal bool
+}
+
+// Placeholders which may be used within a Layout, each is replaced by the corresponding part of the message.
+const (
+	TimestampPlaceholder = "{timestamp}"
+	LevelPlaceholder     = "{level}"
+	SourcePlaceholder    = "{source}"
+	SequencePlaceholder  = "{sequence}"
+	MessagePlaceholder   = "{message}"
+	DataPlaceholder      = "{data}"
+)
+
+// DefaultLayout is the default layout used to render messages.
+const DefaultLayout = "[" + LevelPlaceholder + "] \"" + MessagePlaceholder + "\" " + DataPlaceholder
+
+// DefaultTimeFormat is the default layout used to render the message timestamp.
+const DefaultTimeFormat = time.RFC3339Nano
+
+// Layout sets the layout of messages, placeholders such as LevelPlaceholder are substituted with parts of the message.
+// Data is rendered as a JSON object.
+func Layout(layout string) Option {
+	return func(c *config) {
+		c.layout = layout
+	}
+}
+
+// TimeFormat sets the layout, as understood by time.Format, used to render the message timestamp.
+func TimeFormat(layout string) Option {
+	return func(c *config) {
+		c.timeFormat = layout
+	}
+}
+
+// DelegateTerminalLevels controls if Panic and Fatal messages are logged with Panicf and Fatalf respectively. If
+// disabled, they are logged with Printf and the caller remains responsible for panicking or exiting. Enabled by default.
+func DelegateTerminalLevels(enabled bool) Option {
+	return func(c *config) {
+		c.delegateTerminal = enabled
+	}
+}
+
 // Wrap implements a log/Logger wrapper, allowing the output from logwrap to be sent to the standard Go log package.
 //
-// log/Logger does not support any mechanism for overriding the timestamp, as such the message timestamp will be
-// ignored.
-func Wrap(logger *log.Logger) logwrap.Impl {
+// log/Logger does not support any mechanism for overriding its own timestamp, if the message timestamp is required it
+// should be included in the Layout and the loggers date and time flags disabled. Data values which can not be marshalled
+// into JSON are rendered as strings, without affecting other fields. Write errors are reported with logwrap.ReportError.
+func Wrap(logger *log.Logger, options ...Option) logwrap.Impl {
+	cfg := config{
+		layout:           DefaultLayout,
+		timeFormat:       DefaultTimeFormat,
+		delegateTerminal: true,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
 	return func(ctx context.Context, message logwrap.Message) {
-		data, err := json.Marshal(message.Data)
-		if err != nil {
-			data = []byte("{}")
-		}
+		replacer := strings.NewReplacer(
+			TimestampPlaceholder, message.Timestamp.Format(cfg.timeFormat),
+			LevelPlaceholder, message.Level.String(),
+			SourcePlaceholder, message.Source,
+			SequencePlaceholder, strconv.FormatUint(message.Sequence, 10),
+			MessagePlaceholder, message.Message,
+			DataPlaceholder, string(format.JSONData(message.Data)),
+		)
 
-		var logIt func(format string, v ...interface{})
+		line := replacer.Replace(cfg.layout)
 
-		switch message.Level {
-		case logwrap.Panic:
-			logIt = logger.Panicf
-		case logwrap.Fatal:
-			logIt = logger.Fatalf
-		default:
-			logIt = logger.Printf
+		if cfg.delegateTerminal {
+			switch message.Level {
+			case logwrap.Panic:
+				logger.Panic(line)
+			case logwrap.Fatal:
+				logger.Fatal(line)
+			}
 		}
 
-		logIt("[%s] \"%s\" %s", message.Level.String(), message.Message, data)
+		if err := logger.Output(2, line); err != nil {
+			logwrap.ReportError(ctx, fmt.Errorf("golog: failed to write message: %w", err))
+		}
 	}
 }
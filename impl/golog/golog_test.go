@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log"
 	"testing"
+	"time"
 )
 
 func TestWrap(t *testing.T) {
@@ -23,4 +24,58 @@ func TestWrap(t *testing.T) {
 		actualMessage := outputBuffer.String()
 		assert.Equal(t, expectedMessage, actualMessage)
 	})
+
+	t.Run("wrap renders message timestamp, source and sequence with a configured layout", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		goLogger := log.New(&outputBuffer, "", 0)
+		gologWrap := Wrap(goLogger, Layout("{timestamp} {source}#{sequence} {level} {message} {data}"), TimeFormat("2006-01-02"))
+
+		gologWrap(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Data:      map[string]interface{}{},
+			Timestamp: time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC),
+			Sequence:  5,
+			Source:    "source",
+		})
+
+		expectedMessage := "2020-06-01 source#5 WARN message {}\n"
+		assert.Equal(t, expectedMessage, outputBuffer.String())
+	})
+
+	t.Run("wrap renders values which can not be marshalled without dropping other fields", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		goLogger := log.New(&outputBuffer, "", 0)
+		gologWrap := Wrap(goLogger, Layout("{data}"))
+
+		gologWrap(context.Background(), logwrap.Message{
+			Level: logwrap.Info,
+			Data:  map[string]interface{}{"fn": func() {}, "key": "value"},
+		})
+
+		assert.Regexp(t, `^\{"fn":"0x[0-9a-f]+","key":"value"\}\n$`, outputBuffer.String())
+	})
+
+	t.Run("wrap delegates panic level messages to the logger by default", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		goLogger := log.New(&outputBuffer, "", 0)
+		gologWrap := Wrap(goLogger)
+
+		assert.Panics(t, func() {
+			gologWrap(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+	})
+
+	t.Run("wrap does not panic on panic level messages if delegation is disabled", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		goLogger := log.New(&outputBuffer, "", 0)
+		gologWrap := Wrap(goLogger, DelegateTerminalLevels(false))
+
+		assert.NotPanics(t, func() {
+			gologWrap(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+			gologWrap(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+		})
+
+		assert.Equal(t, "[PANIC] \"message\" {}\n[FATAL] \"message\" {}\n", outputBuffer.String())
+	})
 }
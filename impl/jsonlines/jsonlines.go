@@ -0,0 +1,176 @@
+package jsonlines
+
+import (
+	"bytes"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/shimmeringbee/logwrap/impl/writer"
+	"io"
+	"time"
+)
+
+// Option is a configuration option for the JSON Lines implementation.
+type Option func(*config)
+
+type config struct {
+	timestampKey string
+	levelKey     string
+	sourceKey    string
+	sequenceKey  string
+	messageKey   string
+	dataKey      string
+	timeFormat   string
+}
+
+const (
+	// DefaultTimestampKey is the default key the message timestamp is written under.
+	DefaultTimestampKey = "timestamp"
+	// DefaultLevelKey is the default key the message level is written under.
+	DefaultLevelKey = "level"
+	// DefaultSourceKey is the default key the message source is written under.
+	DefaultSourceKey = "source"
+	// DefaultSequenceKey is the default key the message sequence is written under.
+	DefaultSequenceKey = "sequence"
+	// DefaultMessageKey is the default key the message text is written under.
+	DefaultMessageKey = "message"
+	// DefaultDataKey is the default key the message data is written under.
+	DefaultDataKey = "data"
+	// DefaultTimeFormat is the default layout used to render the message timestamp.
+	DefaultTimeFormat = time.RFC3339Nano
+)
+
+// TimestampKey sets the key the message timestamp is written under, an empty key omits the timestamp.
+func TimestampKey(key string) Option {
+	return func(c *config) {
+		c.timestampKey = key
+	}
+}
+
+// LevelKey sets the key the message level is written under, an empty key omits the level.
+func LevelKey(key string) Option {
+	return func(c *config) {
+		c.levelKey = key
+	}
+}
+
+// SourceKey sets the key the message source is written under, an empty key omits the source.
+func SourceKey(key string) Option {
+	return func(c *config) {
+		c.sourceKey = key
+	}
+}
+
+// SequenceKey sets the key the message sequence is written under, an empty key omits the sequence.
+func SequenceKey(key string) Option {
+	return func(c *config) {
+		c.sequenceKey = key
+	}
+}
+
+// MessageKey sets the key the message text is written under, an empty key omits the message.
+func MessageKey(key string) Option {
+	return func(c *config) {
+		c.messageKey = key
+	}
+}
+
+// DataKey sets the key the message data is nested under. An empty key causes the data to be written at the top level
+// of the object, any data key which collides with another configured key is dropped.
+func DataKey(key string) Option {
+	return func(c *config) {
+		c.dataKey = key
+	}
+}
+
+// TimeFormat sets the layout, as understood by time.Format, used to render the message timestamp.
+func TimeFormat(layout string) Option {
+	return func(c *config) {
+		c.timeFormat = layout
+	}
+}
+
+// Formatter returns a format.Formatter which renders each message as a single JSON object terminated by a new line.
+//
+// Keys within the object are written in a stable order, timestamp, level, source, sequence, message and then data.
+// Data values are normalised with format.Value, any which still can not be marshalled into JSON are rendered with
+// format.String instead.
+func Formatter(options ...Option) format.Formatter {
+	cfg := config{
+		timestampKey: DefaultTimestampKey,
+		levelKey:     DefaultLevelKey,
+		sourceKey:    DefaultSourceKey,
+		sequenceKey:  DefaultSequenceKey,
+		messageKey:   DefaultMessageKey,
+		dataKey:      DefaultDataKey,
+		timeFormat:   DefaultTimeFormat,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	return format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+		return cfg.encode(message), nil
+	})
+}
+
+// Wrap implements a JSON Lines writer, each message is written to the io.Writer as a single JSON object terminated by
+// a new line, as rendered by Formatter. The implementation is safe for concurrent use.
+func Wrap(w io.Writer, options ...Option) logwrap.Impl {
+	return writer.Writer(w, Formatter(options...))
+}
+
+func (c config) encode(message logwrap.Message) []byte {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+
+	first := true
+	usedKeys := map[string]bool{}
+
+	writeField := func(key string, value interface{}) {
+		if key == "" {
+			return
+		}
+
+		if !first {
+			buffer.WriteByte(',')
+		}
+		first = false
+		usedKeys[key] = true
+
+		writeJSON(buffer, key)
+		buffer.WriteByte(':')
+		writeJSON(buffer, value)
+	}
+
+	writeField(c.timestampKey, message.Timestamp.Format(c.timeFormat))
+	writeField(c.levelKey, message.Level.String())
+	writeField(c.sourceKey, message.Source)
+	writeField(c.sequenceKey, message.Sequence)
+	writeField(c.messageKey, message.Message)
+
+	if c.dataKey != "" {
+		writeField(c.dataKey, rawData(message.Data))
+	} else {
+		for _, key := range format.SortedKeys(message.Data) {
+			if !usedKeys[key] {
+				writeField(key, format.Value(message.Data[key]))
+			}
+		}
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.Bytes()
+}
+
+func writeJSON(buffer *bytes.Buffer, value interface{}) {
+	if data, ok := value.(rawData); ok {
+		buffer.Write(format.JSONData(data))
+	} else {
+		buffer.Write(format.JSONValue(value))
+	}
+}
+
+// rawData is a data map which is written with format.JSONData, to permit per field fallback should a value fail to
+// marshal.
+type rawData map[string]interface{}
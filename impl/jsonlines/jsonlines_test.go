@@ -0,0 +1,139 @@
+package jsonlines
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type pointerError struct{}
+
+func (e *pointerError) Error() string {
+	return "failure"
+}
+
+func TestWrap(t *testing.T) {
+	expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("writes a single json object per message with all fields in a stable order", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Data:      map[string]interface{}{"b": 2, "a": "value"},
+			Timestamp: expectedTime,
+			Sequence:  5,
+			Source:    "source",
+		})
+
+		expected := `{"timestamp":"2020-06-01T12:30:00Z","level":"WARN","source":"source","sequence":5,"message":"message","data":{"a":"value","b":2}}` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("typed nil errors are written as null rather than panicking", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		logger := logwrap.New(Wrap(&outputBuffer, TimestampKey(""), SourceKey(""), SequenceKey("")))
+
+		assert.NotPanics(t, func() {
+			logger.Info(context.Background(), "message", logwrap.Datum("e", (*pointerError)(nil)))
+		})
+
+		assert.Equal(t, `{"level":"INFO","message":"message","data":{"e":null}}`+"\n", outputBuffer.String())
+	})
+
+	t.Run("key names and time format can be configured, and empty keys omit the field", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, TimestampKey("ts"), LevelKey("lvl"), SourceKey(""), SequenceKey(""), MessageKey("msg"), DataKey("fields"), TimeFormat("2006-01-02"))
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Info,
+			Message:   "message",
+			Data:      map[string]interface{}{},
+			Timestamp: expectedTime,
+		})
+
+		expected := `{"ts":"2020-06-01","lvl":"INFO","msg":"message","fields":{}}` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("an empty data key writes data at the top level without overwriting other keys", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, TimestampKey(""), SourceKey(""), SequenceKey(""), DataKey(""))
+
+		impl(context.Background(), logwrap.Message{
+			Level:   logwrap.Info,
+			Message: "message",
+			Data:    map[string]interface{}{"key": "value", "message": "clash"},
+		})
+
+		expected := `{"level":"INFO","message":"message","key":"value"}` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("errors are rendered as strings and unmarshalable values fall back without losing other fields", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		impl(context.Background(), logwrap.Message{
+			Level:   logwrap.Error,
+			Message: "message",
+			Data: map[string]interface{}{
+				"err":     errors.New("failure"),
+				"channel": make(chan bool),
+				"key":     "value",
+			},
+		})
+
+		decoded := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &decoded))
+
+		data := decoded["data"].(map[string]interface{})
+		assert.Equal(t, "failure", data["err"])
+		assert.Equal(t, "value", data["key"])
+		assert.True(t, strings.HasPrefix(data["channel"].(string), "0x"))
+	})
+
+	t.Run("concurrent writes do not interleave lines", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+			}()
+		}
+
+		wg.Wait()
+
+		lines := strings.Split(strings.TrimSuffix(outputBuffer.String(), "\n"), "\n")
+		assert.Len(t, lines, 50)
+
+		for _, line := range lines {
+			assert.True(t, json.Valid([]byte(line)))
+		}
+	})
+
+	t.Run("panic level messages are written and then panic", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		assert.Panics(t, func() {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+}
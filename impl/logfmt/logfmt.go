@@ -0,0 +1,129 @@
+package logfmt
+
+import (
+	"bytes"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/shimmeringbee/logwrap/impl/writer"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Option is a configuration option for the logfmt implementation.
+type Option func(*config)
+
+type config struct {
+	timeFormat string
+}
+
+// DefaultTimeFormat is the default layout used to render the message timestamp and any time values.
+const DefaultTimeFormat = time.RFC3339Nano
+
+// TimeFormat sets the layout, as understood by time.Format, used to render the message timestamp and any time values.
+func TimeFormat(layout string) Option {
+	return func(c *config) {
+		c.timeFormat = layout
+	}
+}
+
+const (
+	timeKey     = "time"
+	levelKey    = "level"
+	sourceKey   = "source"
+	sequenceKey = "sequence"
+	messageKey  = "msg"
+)
+
+// Formatter returns a format.Formatter which renders each message as a single line of logfmt key=value pairs.
+//
+// The time, level, source, sequence and msg keys are written first, followed by the message data in key order. Keys
+// have any characters not permitted by logfmt replaced by underscores, values are quoted and escaped where necessary.
+// Data values are rendered with format.String, using the configured time format for time values. Data whose key clashes
+// with a key already written is skipped, so data can not override the level or message.
+func Formatter(options ...Option) format.Formatter {
+	cfg := config{
+		timeFormat: DefaultTimeFormat,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	return format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+		return cfg.encode(message), nil
+	})
+}
+
+// Wrap implements a logfmt writer, each message is written to the io.Writer as a single line of key=value pairs, as
+// rendered by Formatter. The implementation is safe for concurrent use.
+func Wrap(w io.Writer, options ...Option) logwrap.Impl {
+	return writer.Writer(w, Formatter(options...))
+}
+
+func (c config) encode(message logwrap.Message) []byte {
+	buffer := &bytes.Buffer{}
+
+	writePair(buffer, timeKey, message.Timestamp.Format(c.timeFormat))
+	writePair(buffer, levelKey, strings.ToLower(message.Level.String()))
+
+	if message.Source != "" {
+		writePair(buffer, sourceKey, message.Source)
+	}
+
+	writePair(buffer, sequenceKey, strconv.FormatUint(message.Sequence, 10))
+	writePair(buffer, messageKey, message.Message)
+
+	usedKeys := map[string]bool{timeKey: true, levelKey: true, sourceKey: true, sequenceKey: true, messageKey: true}
+
+	for _, key := range format.SortedKeys(message.Data) {
+		if sanitised := sanitiseKey(key); !usedKeys[sanitised] {
+			usedKeys[sanitised] = true
+			writePair(buffer, key, format.String(message.Data[key], c.timeFormat))
+		}
+	}
+
+	buffer.WriteByte('\n')
+	return buffer.Bytes()
+}
+
+func writePair(buffer *bytes.Buffer, key string, value string) {
+	if buffer.Len() > 0 {
+		buffer.WriteByte(' ')
+	}
+
+	buffer.WriteString(sanitiseKey(key))
+	buffer.WriteByte('=')
+	buffer.WriteString(quoteValue(value))
+}
+
+// sanitiseKey replaces characters which are not permitted in a logfmt key with an underscore.
+func sanitiseKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// quoteValue quotes and escapes a value if it is empty or contains characters which would otherwise break parsing.
+func quoteValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,105 @@
+package logfmt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWrap(t *testing.T) {
+	expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("writes a single logfmt line with fixed keys first and data in key order", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message with spaces",
+			Data:      map[string]interface{}{"b": 2, "a": "value"},
+			Timestamp: expectedTime,
+			Sequence:  5,
+			Source:    "source",
+		})
+
+		expected := `time=2020-06-01T12:30:00Z level=warn source=source sequence=5 msg="message with spaces" a=value b=2` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("keys are sanitised and values are quoted and escaped", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, TimeFormat("2006"))
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Info,
+			Message:   "message",
+			Timestamp: expectedTime,
+			Data: map[string]interface{}{
+				"key with=space": "a \"quoted\"\nvalue",
+				"empty":          "",
+				"path":           `c:\dir`,
+			},
+		})
+
+		expected := `time=2020 level=info sequence=0 msg=message empty="" key_with_space="a \"quoted\"\nvalue" path="c:\\dir"` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("data keys clashing with the fixed keys are skipped", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, TimeFormat("2006"))
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Info,
+			Message:   "real",
+			Timestamp: expectedTime,
+			Data: map[string]interface{}{
+				"msg":    "spoofed",
+				"level":  "fatal",
+				"source": "spoofed",
+				"other":  "value",
+			},
+		})
+
+		expected := `time=2020 level=info sequence=0 msg=real other=value` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("nested values, source locations, errors and times are rendered deterministically", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer, TimeFormat(time.RFC3339))
+
+		impl(context.Background(), logwrap.Message{
+			Level:     logwrap.Error,
+			Message:   "message",
+			Timestamp: expectedTime,
+			Data: map[string]interface{}{
+				"duration": 2 * time.Second,
+				"err":      errors.New("failure"),
+				"location": logwrap.SourceLocation{Function: "main.main", File: "/src/main.go", Line: 42},
+				"map":      map[string]int{"z": 1, "a": 2},
+				"nil":      nil,
+				"slice":    []string{"a", "b"},
+				"when":     expectedTime,
+			},
+		})
+
+		expected := `time=2020-06-01T12:30:00Z level=error sequence=0 msg=message duration=2s err=failure location=/src/main.go:42 map="{\"a\":2,\"z\":1}" nil=null slice="[\"a\",\"b\"]" when=2020-06-01T12:30:00Z` + "\n"
+		assert.Equal(t, expected, outputBuffer.String())
+	})
+
+	t.Run("panic level messages are written and then panic", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Wrap(&outputBuffer)
+
+		assert.Panics(t, func() {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+}
@@ -0,0 +1,78 @@
+package logr
+
+import (
+	"context"
+	"errors"
+	realLogr "github.com/go-logr/logr"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"os"
+)
+
+// exit is called on receipt of a Fatal message, it is a variable to permit testing.
+var exit = os.Exit
+
+// Wrap implements a logr wrapper, allowing the output from logwrap to be sent to a logr.Logger.
+//
+// Panic, Fatal and Error messages are logged with Error, if the data contains an error or string under the "err" key,
+// such as set by logwrap.Err, it is passed as the error. Warn and Info are logged at V-level 0, Debug at 1 and Trace at
+// 2. The Source is added with WithName and data is passed as key/value pairs in key order. logr does not support
+// overriding the timestamp, as such the message timestamp will be ignored. As logr does not panic or exit, Wrap does so
+// once the message has been logged for Panic and Fatal messages.
+func Wrap(dest realLogr.Logger) logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		logger := dest
+		if message.Source != "" {
+			logger = logger.WithName(message.Source)
+		}
+
+		var err error
+		keysAndValues := make([]interface{}, 0, len(message.Data)*2)
+
+		for _, key := range format.SortedKeys(message.Data) {
+			if key == errKey {
+				switch e := message.Data[key].(type) {
+				case error:
+					err = e
+					continue
+				case string:
+					err = errors.New(e)
+					continue
+				}
+			}
+
+			keysAndValues = append(keysAndValues, key, message.Data[key])
+		}
+
+		switch message.Level {
+		case logwrap.Panic, logwrap.Fatal, logwrap.Error:
+			logger.Error(err, message.Message, keysAndValues...)
+		default:
+			if err != nil {
+				keysAndValues = append(keysAndValues, errKey, err)
+			}
+
+			logger.V(mapLogLevels(message.Level)).Info(message.Message, keysAndValues...)
+		}
+
+		switch message.Level {
+		case logwrap.Panic:
+			panic(message.Message)
+		case logwrap.Fatal:
+			exit(-1)
+		}
+	}
+}
+
+const errKey = "err"
+
+func mapLogLevels(level logwrap.LogLevel) int {
+	switch level {
+	case logwrap.Debug:
+		return 1
+	case logwrap.Trace:
+		return 2
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,89 @@
+package logr
+
+import (
+	"context"
+	"errors"
+	"github.com/go-logr/logr/funcr"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"testing"
+)
+
+func Test_mapLogLevels(t *testing.T) {
+	t.Run("maps logwrap log levels to logr V-levels", func(t *testing.T) {
+		assert.Equal(t, 0, mapLogLevels(logwrap.Warn))
+		assert.Equal(t, 0, mapLogLevels(logwrap.Info))
+		assert.Equal(t, 1, mapLogLevels(logwrap.Debug))
+		assert.Equal(t, 2, mapLogLevels(logwrap.Trace))
+
+		// Unknown logwrap level.
+		assert.Equal(t, 0, mapLogLevels(logwrap.LogLevel(math.MaxUint64)))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	newLogger := func(output *[]string) logwrap.Impl {
+		return Wrap(funcr.New(func(prefix, args string) {
+			*output = append(*output, prefix+" "+args)
+		}, funcr.Options{Verbosity: 2}))
+	}
+
+	t.Run("wrap sends info messages with source as name and data as key/value pairs", func(t *testing.T) {
+		var output []string
+
+		newLogger(&output)(context.Background(), logwrap.Message{
+			Level:   logwrap.Debug,
+			Message: "message",
+			Source:  "source",
+			Data:    map[string]interface{}{"b": 2, "a": "value"},
+		})
+
+		assert.Equal(t, []string{`source "level"=1 "msg"="message" "a"="value" "b"=2`}, output)
+	})
+
+	t.Run("wrap sends error messages with an error value as the error", func(t *testing.T) {
+		var output []string
+
+		newLogger(&output)(context.Background(), logwrap.Message{
+			Level:   logwrap.Error,
+			Message: "message",
+			Data:    map[string]interface{}{"err": errors.New("failure")},
+		})
+
+		assert.Equal(t, []string{` "msg"="message" "error"="failure"`}, output)
+	})
+
+	t.Run("wrap sends error messages built with Err with the error string as the error", func(t *testing.T) {
+		var output []string
+
+		logger := logwrap.New(newLogger(&output))
+		logger.Error(context.Background(), "message", logwrap.Err(errors.New("failure")))
+
+		assert.Equal(t, []string{` "msg"="message" "error"="failure"`}, output)
+	})
+
+	t.Run("wrap panics after logging panic level messages", func(t *testing.T) {
+		var output []string
+
+		assert.Panics(t, func() {
+			newLogger(&output)(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.Len(t, output, 1)
+	})
+
+	t.Run("wrap exits after logging fatal level messages", func(t *testing.T) {
+		var output []string
+
+		exitCode := 0
+		exit = func(code int) { exitCode = code }
+		defer func() { exit = os.Exit }()
+
+		newLogger(&output)(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.Equal(t, -1, exitCode)
+		assert.Len(t, output, 1)
+	})
+}
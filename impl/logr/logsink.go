@@ -0,0 +1,142 @@
+package logr
+
+import (
+	"context"
+	"fmt"
+	realLogr "github.com/go-logr/logr"
+	"github.com/shimmeringbee/logwrap"
+)
+
+// LogSinkOption is a configuration option for LogSink.
+type LogSinkOption func(*LogSink)
+
+// MaximumVerbosity sets the highest V-level the LogSink reports as enabled, by default all V-levels are enabled.
+func MaximumVerbosity(level int) LogSinkOption {
+	return func(s *LogSink) {
+		s.maximumVerbosity = level
+	}
+}
+
+// MissingValue is used as the value of a key/value pair provided without a value.
+const MissingValue = "(MISSING)"
+
+// LogSink is a logr.LogSink which logs through a logwrap Logger, allowing libraries which take a logr.Logger to share
+// logwraps output pipeline. It should be constructed with NewLogSink, and is usually passed to logr.New.
+type LogSink struct {
+	logger           logwrap.Logger
+	maximumVerbosity int
+	named            bool
+	options          []logwrap.Option
+}
+
+var _ realLogr.LogSink = (*LogSink)(nil)
+
+// NewLogSink constructs a LogSink which logs through the logwrap Logger provided.
+//
+// V-level 0 is mapped to Info, 1 to Debug and anything higher to Trace, Error calls are logged at Error with the error
+// added with logwrap.Err. The first name given with WithName is used as the message Source, further names are added
+// with logwrap.Trail. Values given with WithValues are added to every message. logr does not carry a context, as such
+// messages are logged with context.Background.
+func NewLogSink(logger logwrap.Logger, options ...LogSinkOption) *LogSink {
+	s := &LogSink{
+		logger:           logger,
+		maximumVerbosity: int(^uint(0) >> 1),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Init receives runtime information from logr, it is not used.
+func (s *LogSink) Init(realLogr.RuntimeInfo) {
+}
+
+// Enabled reports if the V-level is at or below the maximum verbosity.
+func (s *LogSink) Enabled(level int) bool {
+	return level <= s.maximumVerbosity
+}
+
+// Info logs a non-error message at the level mapped from the V-level.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(mapVerbosity(level), msg, keysAndValues)
+}
+
+// Error logs an error message, with the error added to the messages data.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append(keysAndValues, logwrap.Err(err))
+	}
+
+	s.log(logwrap.Error, msg, keysAndValues)
+}
+
+// WithValues returns a new LogSink which adds the key/value pairs to every message.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) realLogr.LogSink {
+	clone := *s
+	clone.options = appendKeysAndValues(append([]logwrap.Option{}, s.options...), keysAndValues)
+
+	return &clone
+}
+
+// WithName returns a new LogSink with the name added, the first name is used as the Source, later names as a Trail.
+func (s *LogSink) WithName(name string) realLogr.LogSink {
+	clone := *s
+	clone.options = append([]logwrap.Option{}, s.options...)
+
+	if s.named {
+		clone.options = append(clone.options, logwrap.Trail(name))
+	} else {
+		clone.options = append(clone.options, logwrap.Source(name))
+		clone.named = true
+	}
+
+	return &clone
+}
+
+func (s *LogSink) log(level logwrap.LogLevel, msg string, keysAndValues []interface{}) {
+	options := make([]logwrap.Option, 0, len(s.options)+len(keysAndValues)/2+1)
+	options = append(options, s.options...)
+	options = appendKeysAndValues(options, keysAndValues)
+	options = append(options, logwrap.Level(level))
+
+	s.logger.Log(context.Background(), msg, options...)
+}
+
+// appendKeysAndValues converts logr key/value pairs into options, logwrap options may also be passed directly.
+func appendKeysAndValues(options []logwrap.Option, keysAndValues []interface{}) []logwrap.Option {
+	for i := 0; i < len(keysAndValues); i++ {
+		if option, ok := keysAndValues[i].(logwrap.Option); ok {
+			options = append(options, option)
+			continue
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		var value interface{} = MissingValue
+		if i+1 < len(keysAndValues) {
+			i++
+			value = keysAndValues[i]
+		}
+
+		options = append(options, logwrap.Datum(key, value))
+	}
+
+	return options
+}
+
+func mapVerbosity(level int) logwrap.LogLevel {
+	switch {
+	case level <= 0:
+		return logwrap.Info
+	case level == 1:
+		return logwrap.Debug
+	default:
+		return logwrap.Trace
+	}
+}
@@ -0,0 +1,82 @@
+package logr
+
+import (
+	"errors"
+	realLogr "github.com/go-logr/logr"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_mapVerbosity(t *testing.T) {
+	t.Run("maps logr V-levels to logwrap log levels", func(t *testing.T) {
+		assert.Equal(t, logwrap.Info, mapVerbosity(0))
+		assert.Equal(t, logwrap.Debug, mapVerbosity(1))
+		assert.Equal(t, logwrap.Trace, mapVerbosity(2))
+		assert.Equal(t, logwrap.Trace, mapVerbosity(10))
+	})
+}
+
+func TestLogSink(t *testing.T) {
+	t.Run("info sends a message with level mapped from the V-level and key/value pairs as data", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realLogr.New(NewLogSink(logwrap.New(c.Impl())))
+
+		logger.V(1).Info("message", "key", "value", 5, "number", "missing")
+
+		m := c.Messages()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, logwrap.Debug, m[0].Level)
+		assert.Equal(t, "message", m[0].Message)
+		assert.Equal(t, "value", m[0].Data["key"])
+		assert.Equal(t, "number", m[0].Data["5"])
+		assert.Equal(t, MissingValue, m[0].Data["missing"])
+	})
+
+	t.Run("error sends an error level message with the error attached", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realLogr.New(NewLogSink(logwrap.New(c.Impl())))
+
+		logger.Error(errors.New("failure"), "message")
+		logger.Error(nil, "message")
+
+		m := c.Messages()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, logwrap.Error, m[0].Level)
+		assert.Equal(t, "failure", m[0].Data["err"])
+		assert.NotContains(t, m[1].Data, "err")
+	})
+
+	t.Run("with name sets the source and then a trail, with values adds data to every message", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realLogr.New(NewLogSink(logwrap.New(c.Impl())))
+
+		logger.WithName("outer").WithName("inner").WithValues("key", "value").Info("message")
+		logger.Info("unnamed")
+
+		m := c.Messages()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, "outer", m[0].Source)
+		assert.Equal(t, "inner", m[0].Data["trail"])
+		assert.Equal(t, "value", m[0].Data["key"])
+
+		assert.Equal(t, "", m[1].Source)
+		assert.NotContains(t, m[1].Data, "key")
+	})
+
+	t.Run("V-levels above the maximum verbosity are not enabled", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realLogr.New(NewLogSink(logwrap.New(c.Impl()), MaximumVerbosity(1)))
+
+		logger.V(1).Info("enabled")
+		logger.V(2).Info("disabled")
+
+		m := c.Messages()
+		assert.Len(t, m, 1)
+		assert.Equal(t, "enabled", m[0].Message)
+	})
+}
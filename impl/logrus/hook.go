@@ -0,0 +1,90 @@
+package logrus
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	realLogrus "github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook which feeds logrus entries into a logwrap Logger, allowing output from dependencies that log via
+// logrus to pass through logwraps filters and sinks. It should be constructed with NewHook or NewLoggerHook.
+//
+// To prevent entries being output twice, the logrus logger's own output should usually be discarded.
+type Hook struct {
+	logger logwrap.Logger
+	levels []realLogrus.Level
+}
+
+var _ realLogrus.Hook = (*Hook)(nil)
+
+// NewHook constructs a Hook which sends logrus entries to a logwrap implementation. If no levels are provided the hook
+// fires for all levels.
+func NewHook(impl logwrap.Impl, levels ...realLogrus.Level) *Hook {
+	return NewLoggerHook(logwrap.New(impl), levels...)
+}
+
+// NewLoggerHook constructs a Hook which logs logrus entries through a logwrap Logger, as such any options on the logger
+// or within the entries context are applied. If no levels are provided the hook fires for all levels.
+//
+// Entry fields, message and time are preserved, the callers location if reported is added as a logwrap.SourceLocation
+// under logwrap.SourceTraceField. Panic and Fatal entries are logged at Error, as logrus itself panics or exits once
+// hooks have fired.
+func NewLoggerHook(logger logwrap.Logger, levels ...realLogrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = realLogrus.AllLevels
+	}
+
+	return &Hook{
+		logger: logger,
+		levels: levels,
+	}
+}
+
+// Levels returns the logrus levels the hook fires for.
+func (h *Hook) Levels() []realLogrus.Level {
+	return h.levels
+}
+
+// Fire logs the logrus entry through the logwrap Logger.
+func (h *Hook) Fire(entry *realLogrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	options := []logwrap.Option{
+		logwrap.Data(logwrap.List(entry.Data)),
+		logwrap.Level(mapLogrusLevels(entry.Level)),
+		func(message *logwrap.Message) {
+			message.Timestamp = entry.Time
+		},
+	}
+
+	if entry.Caller != nil {
+		options = append(options, logwrap.Datum(logwrap.SourceTraceField, logwrap.SourceLocation{
+			Function: entry.Caller.Function,
+			File:     entry.Caller.File,
+			Line:     entry.Caller.Line,
+		}))
+	}
+
+	h.logger.Log(ctx, entry.Message, options...)
+	return nil
+}
+
+func mapLogrusLevels(level realLogrus.Level) logwrap.LogLevel {
+	switch level {
+	case realLogrus.PanicLevel, realLogrus.FatalLevel, realLogrus.ErrorLevel:
+		return logwrap.Error
+	case realLogrus.WarnLevel:
+		return logwrap.Warn
+	case realLogrus.InfoLevel:
+		return logwrap.Info
+	case realLogrus.DebugLevel:
+		return logwrap.Debug
+	case realLogrus.TraceLevel:
+		return logwrap.Trace
+	default:
+		return logwrap.Info
+	}
+}
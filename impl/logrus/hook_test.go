@@ -0,0 +1,75 @@
+package logrus
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_mapLogrusLevels(t *testing.T) {
+	t.Run("maps logrus to logwrap log levels, never above error", func(t *testing.T) {
+		assert.Equal(t, logwrap.Error, mapLogrusLevels(logrus.PanicLevel))
+		assert.Equal(t, logwrap.Error, mapLogrusLevels(logrus.FatalLevel))
+		assert.Equal(t, logwrap.Error, mapLogrusLevels(logrus.ErrorLevel))
+		assert.Equal(t, logwrap.Warn, mapLogrusLevels(logrus.WarnLevel))
+		assert.Equal(t, logwrap.Info, mapLogrusLevels(logrus.InfoLevel))
+		assert.Equal(t, logwrap.Debug, mapLogrusLevels(logrus.DebugLevel))
+		assert.Equal(t, logwrap.Trace, mapLogrusLevels(logrus.TraceLevel))
+
+		// Unknown logrus level.
+		assert.Equal(t, logwrap.Info, mapLogrusLevels(logrus.Level(math.MaxUint32)))
+	})
+}
+
+func TestHook(t *testing.T) {
+	t.Run("hook sends logrus entries with message, level, time, fields and context", func(t *testing.T) {
+		c := capture.NewCapture()
+		logwrapLogger := logwrap.New(c.Impl())
+
+		ctx := logwrapLogger.AddOptionsToContext(context.Background(), logwrap.Source("source"))
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+		logger.SetLevel(logrus.TraceLevel)
+		logger.ReportCaller = true
+		logger.AddHook(NewLoggerHook(logwrapLogger))
+
+		expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+		logger.WithContext(ctx).WithTime(expectedTime).WithField("key", "value").Warn("message")
+
+		m := c.Messages()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, logwrap.Warn, m[0].Level)
+		assert.Equal(t, "message", m[0].Message)
+		assert.Equal(t, expectedTime, m[0].Timestamp)
+		assert.Equal(t, "value", m[0].Data["key"])
+		assert.Equal(t, "source", m[0].Source)
+
+		location, ok := m[0].Data[logwrap.SourceTraceField].(logwrap.SourceLocation)
+		assert.True(t, ok)
+		assert.Contains(t, location.File, "hook_test.go")
+	})
+
+	t.Run("hook only fires for the levels provided", func(t *testing.T) {
+		c := capture.NewCapture()
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+		logger.AddHook(NewHook(c.Impl(), logrus.ErrorLevel))
+
+		logger.Info("ignored")
+		logger.Error("fired")
+
+		m := c.Messages()
+		assert.Len(t, m, 1)
+		assert.Equal(t, "fired", m[0].Message)
+	})
+}
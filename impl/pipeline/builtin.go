@@ -0,0 +1,439 @@
+package pipeline
+
+import (
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/async"
+	"github.com/shimmeringbee/logwrap/impl/console"
+	"github.com/shimmeringbee/logwrap/impl/dedupe"
+	"github.com/shimmeringbee/logwrap/impl/discard"
+	"github.com/shimmeringbee/logwrap/impl/filter"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/shimmeringbee/logwrap/impl/jsonlines"
+	"github.com/shimmeringbee/logwrap/impl/logfmt"
+	"github.com/shimmeringbee/logwrap/impl/postlogoptions"
+	"github.com/shimmeringbee/logwrap/impl/router"
+	"github.com/shimmeringbee/logwrap/impl/sample"
+	"github.com/shimmeringbee/logwrap/impl/tee"
+	"github.com/shimmeringbee/logwrap/impl/writer"
+	"io"
+	"os"
+)
+
+// DefaultRedactedValue is the default value redacted fields are replaced with.
+const DefaultRedactedValue = "[REDACTED]"
+
+func registerBuiltins(r *Registry) {
+	r.Register("discard", buildDiscard)
+	r.Register("writer", buildWriter)
+	r.Register("filter", buildFilter)
+	r.Register("level", buildLevel)
+	r.Register("redact", buildRedact)
+	r.Register("sample", buildSample)
+	r.Register("dedupe", buildDedupe)
+	r.Register("async", buildAsync)
+	r.Register("tee", buildTee)
+	r.Register("router", buildRouter)
+}
+
+// buildDiscard builds a stage which drops all messages.
+func buildDiscard(Node) (logwrap.Sink, error) {
+	return logwrap.NoLifecycle(discard.Discard()), nil
+}
+
+// buildWriter builds a stage which writes messages to stdout, stderr or a file, in jsonlines, logfmt or console format.
+//
+//	type: writer
+//	output: stdout | stderr | <path>
+//	format: jsonlines | logfmt | console
+//	timeFormat: <layout>
+//	colour: true | false
+func buildWriter(n Node) (logwrap.Sink, error) {
+	output, err := n.String("output", "stdout")
+	if err != nil {
+		return nil, err
+	}
+
+	formatName, err := n.String("format", "jsonlines")
+	if err != nil {
+		return nil, err
+	}
+
+	timeFormat, err := n.String("timeFormat", "")
+	if err != nil {
+		return nil, err
+	}
+
+	colour, err := n.Bool("colour", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var formatter format.Formatter
+
+	switch formatName {
+	case "jsonlines":
+		var options []jsonlines.Option
+		if timeFormat != "" {
+			options = append(options, jsonlines.TimeFormat(timeFormat))
+		}
+		formatter = jsonlines.Formatter(options...)
+	case "logfmt":
+		var options []logfmt.Option
+		if timeFormat != "" {
+			options = append(options, logfmt.TimeFormat(timeFormat))
+		}
+		formatter = logfmt.Formatter(options...)
+	case "console":
+		options := []console.Option{console.Colour(colour)}
+		if timeFormat != "" {
+			options = append(options, console.TimeFormat(timeFormat))
+		}
+		formatter = console.Formatter(options...)
+	default:
+		return nil, n.Errorf("format", "unknown format %q, expected one of: jsonlines, logfmt, console", formatName)
+	}
+
+	var w io.Writer
+
+	switch output {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	case "":
+		return nil, n.Errorf("output", "expected stdout, stderr or a file path")
+	default:
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, n.Errorf("output", "failed to open file: %v", err)
+		}
+		w = file
+	}
+
+	return writer.WriterSink(w, formatter), nil
+}
+
+// buildFilter builds a stage which passes messages matching a filter expression to the next stage.
+//
+//	type: filter
+//	expression: <filter expression>
+//	next: <stage>
+func buildFilter(n Node) (logwrap.Sink, error) {
+	expression, err := n.String("expression", "")
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := filter.Compile(expression)
+	if err != nil {
+		return nil, n.Errorf("expression", "%v", err)
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.FilterSink(next, match), nil
+}
+
+// buildLevel builds a stage which passes messages at the level or more severe to the next stage.
+//
+//	type: level
+//	level: <level>
+//	next: <stage>
+func buildLevel(n Node) (logwrap.Sink, error) {
+	if !n.Has("level") {
+		return nil, n.Errorf("level", "is required")
+	}
+
+	level, err := n.Level("level", logwrap.Info)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.FilterSink(next, func(message logwrap.Message) bool {
+		return message.Level <= level
+	}), nil
+}
+
+// buildRedact builds a stage which replaces the values of data fields before passing messages to the next stage.
+//
+//	type: redact
+//	fields: [<field>, ...]
+//	replacement: <value>
+//	next: <stage>
+func buildRedact(n Node) (logwrap.Sink, error) {
+	fields, err := n.Strings("fields")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 {
+		return nil, n.Errorf("fields", "at least one field is required")
+	}
+
+	replacement, err := n.String("replacement", DefaultRedactedValue)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	return postlogoptions.PostLogOptionsSink(next, func(message *logwrap.Message) {
+		for _, field := range fields {
+			if _, found := message.Data[field]; found {
+				message.Data[field] = replacement
+			}
+		}
+	}), nil
+}
+
+// buildSample builds a stage which samples messages before passing them to the next stage, either one in every messages
+// or the first messages then one in every thereafter within each interval. If thereafter is omitted or zero, all
+// messages after the first are dropped.
+//
+//	type: sample
+//	every: <count>
+//	first: <count>
+//	thereafter: <count>
+//	interval: <duration>
+//	threshold: <level>
+//	next: <stage>
+func buildSample(n Node) (logwrap.Sink, error) {
+	every, err := n.Int("every", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := n.Int("first", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	thereafter, err := n.Int("thereafter", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := n.Duration("interval", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := n.Level("threshold", sample.DefaultThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sample.Option{sample.Threshold(threshold)}
+
+	switch {
+	case every > 0 && (first > 0 || thereafter > 0):
+		return nil, n.Errorf("every", "can not be combined with first and thereafter")
+	case every < 0 || first < 0 || thereafter < 0:
+		return nil, n.Errorf("every", "counts must not be negative")
+	case every > 0:
+		options = append(options, sample.Every(uint64(every)))
+	case first > 0 || thereafter > 0:
+		options = append(options, sample.FirstThenEvery(uint64(first), uint64(thereafter), interval))
+	default:
+		return nil, n.Errorf("every", "either every, first or thereafter is required")
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	return logwrap.WithLifecycle(sample.Sample(next.Impl(), options...), next.Flush, next.Close), nil
+}
+
+// buildDedupe builds a stage which collapses repeated messages before passing them to the next stage.
+//
+//	type: dedupe
+//	window: <duration>
+//	fields: [<field>, ...]
+//	consecutive: true | false
+//	maximumRuns: <count>
+//	next: <stage>
+func buildDedupe(n Node) (logwrap.Sink, error) {
+	window, err := n.Duration("window", dedupe.DefaultWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := n.Strings("fields")
+	if err != nil {
+		return nil, err
+	}
+
+	consecutive, err := n.Bool("consecutive", false)
+	if err != nil {
+		return nil, err
+	}
+
+	maximumRuns, err := n.Int("maximumRuns", dedupe.DefaultMaximumRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	if maximumRuns < 0 {
+		return nil, n.Errorf("maximumRuns", "must not be negative")
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	options := []dedupe.Option{dedupe.Window(window), dedupe.KeyFields(fields...), dedupe.MaximumRuns(maximumRuns)}
+	if consecutive {
+		options = append(options, dedupe.Consecutive())
+	}
+
+	return dedupe.NewDedupeSink(next, options...), nil
+}
+
+// buildAsync builds a stage which delivers messages to the next stage on its own go routine.
+//
+//	type: async
+//	queueSize: <count>
+//	whenFull: block | dropNewest | dropOldest | dropBelowLevel
+//	dropLevel: <level>
+//	next: <stage>
+func buildAsync(n Node) (logwrap.Sink, error) {
+	queueSize, err := n.Int("queueSize", async.DefaultQueueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if queueSize < 0 {
+		return nil, n.Errorf("queueSize", "must not be negative")
+	}
+
+	whenFull, err := n.String("whenFull", "block")
+	if err != nil {
+		return nil, err
+	}
+
+	policies := map[string]async.Policy{
+		"block":          async.Block,
+		"dropNewest":     async.DropNewest,
+		"dropOldest":     async.DropOldest,
+		"dropBelowLevel": async.DropBelowLevel,
+	}
+
+	policy, found := policies[whenFull]
+	if !found {
+		return nil, n.Errorf("whenFull", "unknown policy %q, expected one of: block, dropNewest, dropOldest, dropBelowLevel", whenFull)
+	}
+
+	dropLevel, err := n.Level("dropLevel", logwrap.Info)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := n.Sink("next")
+	if err != nil {
+		return nil, err
+	}
+
+	return async.NewAsyncSink(next, async.QueueSize(queueSize), async.WhenFull(policy), async.DropLevel(dropLevel)), nil
+}
+
+// buildTee builds a stage which passes messages to each of its destinations.
+//
+//	type: tee
+//	destinations: [<stage>, ...]
+func buildTee(n Node) (logwrap.Sink, error) {
+	destinations, err := n.Sinks("destinations")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(destinations) == 0 {
+		return nil, n.Errorf("destinations", "at least one destination is required")
+	}
+
+	return tee.TeeSink(destinations...), nil
+}
+
+// buildRouter builds a stage which passes messages to the first, or all, routes whose filter expression matches, and
+// otherwise to the default. A route without a match expression matches every message.
+//
+//	type: router
+//	mode: first | all
+//	routes:
+//	  - match: <filter expression>
+//	    to: <stage>
+//	default: <stage>
+func buildRouter(n Node) (logwrap.Sink, error) {
+	mode, err := n.String("mode", "first")
+	if err != nil {
+		return nil, err
+	}
+
+	var options []router.Option
+
+	switch mode {
+	case "first":
+	case "all":
+		options = append(options, router.AllMatches())
+	default:
+		return nil, n.Errorf("mode", "unknown mode %q, expected first or all", mode)
+	}
+
+	routes, err := n.Nodes("routes")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(routes) == 0 && !n.Has("default") {
+		return nil, n.Errorf("routes", "at least one route or a default is required")
+	}
+
+	for _, route := range routes {
+		expression, err := route.String("match", "")
+		if err != nil {
+			return nil, err
+		}
+
+		var matchers []router.Matcher
+
+		if expression != "" {
+			match, err := filter.Compile(expression)
+			if err != nil {
+				return nil, route.Errorf("match", "%v", err)
+			}
+
+			matchers = append(matchers, match)
+		}
+
+		dest, err := route.Sink("to")
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, router.RouteSink(dest, matchers...))
+	}
+
+	if n.Has("default") {
+		dest, err := n.Sink("default")
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, router.DefaultSink(dest))
+	}
+
+	return router.RouterSink(options...), nil
+}
@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"sync"
+)
+
+// Parse decodes a YAML or JSON configuration document and builds the pipeline it describes with the registry. Each
+// stage is a mapping with a type naming a stage in the registry, for example:
+//
+//	type: router
+//	routes:
+//	  - match: 'source == "audit"'
+//	    to: { type: writer, output: audit.log }
+//	  - match: 'level <= warn'
+//	    to: { type: alerts }
+//	default:
+//	  type: level
+//	  level: info
+//	  next: { type: writer, output: stdout, format: console }
+//
+// Unknown stage types, unknown settings and invalid values are rejected with an error naming their location in the
+// document.
+func Parse(registry *Registry, document []byte) (logwrap.Sink, error) {
+	var parsed interface{}
+
+	if err := yaml.Unmarshal(document, &parsed); err != nil {
+		return nil, fmt.Errorf("pipeline: failed to parse document: %w", err)
+	}
+
+	return registry.Build(parsed)
+}
+
+// NewPipeline initialises a new Pipeline built from the configuration document, the handle to be provided to logwrap
+// should be obtained by calling Impl(), or the Pipeline may be given to logwrap.NewWithSink.
+func NewPipeline(registry *Registry, document []byte) (*Pipeline, error) {
+	sink, err := Parse(registry, document)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{
+		registry: registry,
+		mutex:    &sync.RWMutex{},
+		sink:     sink,
+		impl:     sink.Impl(),
+	}, nil
+}
+
+// NewPipelineFromFile initialises a new Pipeline as with NewPipeline, reading the document from a file.
+func NewPipelineFromFile(registry *Registry, path string) (*Pipeline, error) {
+	document, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: failed to read document: %w", err)
+	}
+
+	return NewPipeline(registry, document)
+}
+
+// Pipeline is a structure which provides a log implementation built from a configuration document, which may be
+// replaced while in use.
+type Pipeline struct {
+	registry *Registry
+	mutex    *sync.RWMutex
+	sink     logwrap.Sink
+	impl     logwrap.Impl
+}
+
+// Impl returns an implementation that can be passed to logwrap, it always delivers to the current pipeline.
+func (p *Pipeline) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		p.mutex.RLock()
+		defer p.mutex.RUnlock()
+
+		p.impl(ctx, message)
+	}
+}
+
+// Reload builds a new pipeline from the configuration document and atomically swaps it for the current pipeline, which
+// is then closed once messages being delivered to it have completed. If the document is invalid the current pipeline is
+// kept and the error returned. Messages logged during Reload are delivered by either the old or new pipeline.
+func (p *Pipeline) Reload(ctx context.Context, document []byte) error {
+	sink, err := Parse(p.registry, document)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	old := p.sink
+	p.sink = sink
+	p.impl = sink.Impl()
+	p.mutex.Unlock()
+
+	return old.Close(ctx)
+}
+
+// ReloadFile reloads the pipeline as with Reload, reading the document from a file.
+func (p *Pipeline) ReloadFile(ctx context.Context, path string) error {
+	document, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("pipeline: failed to read document: %w", err)
+	}
+
+	return p.Reload(ctx, document)
+}
+
+// Flush flushes the current pipeline.
+func (p *Pipeline) Flush(ctx context.Context) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.sink.Flush(ctx)
+}
+
+// Close closes the current pipeline. Pipeline satisfies logwrap.Sink.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.sink.Close(ctx)
+}
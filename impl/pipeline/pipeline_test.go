@@ -0,0 +1,189 @@
+package pipeline
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("builds a routed pipeline with levels, redaction and filters", func(t *testing.T) {
+		audit := capture.NewCapture()
+		alerts := capture.NewCapture()
+		stdout := capture.NewCapture()
+
+		registry := NewRegistry()
+		registry.RegisterImpl("audit", audit.Impl())
+		registry.RegisterImpl("alerts", alerts.Impl())
+		registry.RegisterImpl("stdout", stdout.Impl())
+
+		document := `
+type: redact
+fields: [password]
+next:
+  type: router
+  routes:
+    - match: 'source == "audit"'
+      to: { type: audit }
+    - match: 'level <= warn'
+      to: { type: alerts }
+  default:
+    type: level
+    level: info
+    next:
+      type: filter
+      expression: '!data.noisy'
+      next: { type: stdout }
+`
+
+		sink, err := Parse(registry, []byte(document))
+		assert.NoError(t, err)
+
+		logger := logwrap.New(sink.Impl())
+		logger.Info(context.Background(), "login", logwrap.Source("audit"), logwrap.Datum("password", "secret"))
+		logger.Error(context.Background(), "failure")
+		logger.Info(context.Background(), "progress")
+		logger.Info(context.Background(), "chatter", logwrap.Datum("noisy", true))
+		logger.Debug(context.Background(), "detail")
+
+		assert.Len(t, audit.Messages(), 1)
+		assert.Equal(t, DefaultRedactedValue, audit.Messages()[0].Data["password"])
+		assert.Len(t, alerts.Messages(), 1)
+		assert.Len(t, stdout.Messages(), 1)
+		assert.Equal(t, "progress", stdout.Messages()[0].Message)
+	})
+
+	t.Run("builds a pipeline from JSON with tee, sample, dedupe and async stages", func(t *testing.T) {
+		sampled := capture.NewCapture()
+		deduped := capture.NewCapture()
+
+		registry := NewRegistry()
+		registry.RegisterImpl("sampled", sampled.Impl())
+		registry.RegisterImpl("deduped", deduped.Impl())
+
+		document := `{
+			"type": "async",
+			"queueSize": 16,
+			"next": {
+				"type": "tee",
+				"destinations": [
+					{"type": "sample", "every": 2, "next": {"type": "sampled"}},
+					{"type": "dedupe", "window": "1m", "next": {"type": "deduped"}}
+				]
+			}
+		}`
+
+		sink, err := Parse(registry, []byte(document))
+		assert.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			sink.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		}
+
+		assert.NoError(t, sink.Close(context.Background()))
+
+		assert.Len(t, sampled.Messages(), 2)
+		assert.Len(t, deduped.Messages(), 2)
+		assert.Equal(t, uint64(3), deduped.Messages()[1].Data["repeated"])
+	})
+
+	t.Run("writer stages write formatted messages to files which are closed with the pipeline", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "pipeline")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "output.log")
+
+		sink, err := Parse(NewRegistry(), []byte("type: writer\nformat: logfmt\ntimeFormat: '2006'\noutput: '"+path+"'\n"))
+		assert.NoError(t, err)
+
+		sink.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		assert.NoError(t, sink.Close(context.Background()))
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "time=0001 level=info sequence=0 msg=message\n", string(data))
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	t.Run("reload swaps the pipeline behind an existing logger and closes the old pipeline", func(t *testing.T) {
+		first := capture.NewCapture()
+		second := capture.NewCapture()
+		closed := false
+
+		registry := NewRegistry()
+		registry.Register("first", func(Node) (logwrap.Sink, error) {
+			return logwrap.WithLifecycle(first.Impl(), nil, func(context.Context) error {
+				closed = true
+				return nil
+			}), nil
+		})
+		registry.RegisterImpl("second", second.Impl())
+
+		p, err := NewPipeline(registry, []byte("type: first"))
+		assert.NoError(t, err)
+
+		logger := logwrap.NewWithSink(p)
+		logger.Info(context.Background(), "one")
+
+		assert.NoError(t, p.Reload(context.Background(), []byte("type: second")))
+		assert.True(t, closed)
+
+		logger.Info(context.Background(), "two")
+
+		assert.Len(t, first.Messages(), 1)
+		assert.Len(t, second.Messages(), 1)
+		assert.Equal(t, "two", second.Messages()[0].Message)
+	})
+
+	t.Run("an invalid document on reload keeps the current pipeline", func(t *testing.T) {
+		c := capture.NewCapture()
+		registry := NewRegistry()
+		registry.RegisterImpl("capture", c.Impl())
+
+		p, err := NewPipeline(registry, []byte("type: capture"))
+		assert.NoError(t, err)
+
+		err = p.Reload(context.Background(), []byte("type: missing"))
+		assert.Error(t, err)
+
+		p.Impl()(context.Background(), logwrap.Message{Message: "message"})
+		assert.Len(t, c.Messages(), 1)
+	})
+
+	t.Run("pipelines can be loaded and reloaded from files", func(t *testing.T) {
+		c := capture.NewCapture()
+		registry := NewRegistry()
+		registry.RegisterImpl("capture", c.Impl())
+
+		dir, err := ioutil.TempDir("", "pipeline")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "logging.yaml")
+		assert.NoError(t, ioutil.WriteFile(path, []byte("type: discard"), 0644))
+
+		p, err := NewPipelineFromFile(registry, path)
+		assert.NoError(t, err)
+
+		p.Impl()(context.Background(), logwrap.Message{Message: "one"})
+
+		assert.NoError(t, ioutil.WriteFile(path, []byte("type: capture"), 0644))
+		assert.NoError(t, p.ReloadFile(context.Background(), path))
+
+		p.Impl()(context.Background(), logwrap.Message{Message: "two"})
+
+		assert.Len(t, c.Messages(), 1)
+		assert.Equal(t, "two", c.Messages()[0].Message)
+
+		_, err = NewPipelineFromFile(registry, filepath.Join(dir, "missing.yaml"))
+		assert.True(t, strings.HasPrefix(err.Error(), "pipeline: failed to read document: "))
+	})
+}
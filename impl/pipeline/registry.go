@@ -0,0 +1,395 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory builds a stage of a pipeline from its node in the configuration document. Factories read their settings
+// and any following stages from the node, every key in the node must be read or the document is rejected.
+type Factory func(node Node) (logwrap.Sink, error)
+
+// NewRegistry initialises a new Registry containing the built-in stage types.
+func NewRegistry() *Registry {
+	r := &Registry{
+		mutex:     &sync.RWMutex{},
+		factories: map[string]Factory{},
+	}
+
+	registerBuiltins(r)
+
+	return r
+}
+
+// Registry holds the factories for each stage type which may be referenced by a configuration document.
+type Registry struct {
+	mutex     *sync.RWMutex
+	factories map[string]Factory
+}
+
+// Register adds a stage type to the registry, replacing any existing type of the same name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.factories[name] = factory
+}
+
+// RegisterImpl adds a stage type which always refers to the implementation provided, such as one constructed by the
+// application. The stage accepts no settings.
+func (r *Registry) RegisterImpl(name string, impl logwrap.Impl) {
+	r.RegisterSink(name, logwrap.NoLifecycle(impl))
+}
+
+// RegisterSink adds a stage type which always refers to the Sink provided. As the Sink outlives any single pipeline it
+// is flushed but never closed by the pipeline, the application remains responsible for closing it.
+func (r *Registry) RegisterSink(name string, sink logwrap.Sink) {
+	shared := logwrap.WithLifecycle(sink.Impl(), sink.Flush, sink.Flush)
+
+	r.Register(name, func(Node) (logwrap.Sink, error) {
+		return shared, nil
+	})
+}
+
+// Build constructs a pipeline from a parsed configuration document, as produced by unmarshalling YAML or JSON into an
+// interface{}. The root of the document is the first stage of the pipeline. If the document is invalid, any stages
+// already built are closed.
+func (r *Registry) Build(document interface{}) (logwrap.Sink, error) {
+	b := &builder{registry: r}
+
+	sink, err := b.build("pipeline", document)
+
+	if err == nil {
+		for _, n := range b.nodes {
+			if err = n.checkUnused(); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		_ = logwrap.CloseAll(context.Background(), b.built...)
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (r *Registry) factory(name string) (Factory, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	factory, found := r.factories[name]
+	return factory, found
+}
+
+func (r *Registry) names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type builder struct {
+	registry *Registry
+	nodes    []Node
+	built    []logwrap.Lifecycle
+}
+
+func (b *builder) node(path string, value interface{}) (Node, error) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return Node{}, fmt.Errorf("pipeline: %s: expected a mapping but found %s", path, describe(value))
+	}
+
+	n := Node{path: path, values: values, used: map[string]bool{}, builder: b}
+	b.nodes = append(b.nodes, n)
+
+	return n, nil
+}
+
+func (b *builder) build(path string, value interface{}) (logwrap.Sink, error) {
+	n, err := b.node(path, value)
+	if err != nil {
+		return nil, err
+	}
+
+	stageType, err := n.String("type", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if stageType == "" {
+		return nil, n.Errorf("type", "a stage type is required, one of: %s", strings.Join(b.registry.names(), ", "))
+	}
+
+	factory, found := b.registry.factory(stageType)
+	if !found {
+		return nil, n.Errorf("type", "unknown stage type %q, expected one of: %s", stageType, strings.Join(b.registry.names(), ", "))
+	}
+
+	sink, err := factory(n)
+	if err != nil {
+		return nil, err
+	}
+
+	b.built = append(b.built, sink)
+	return sink, nil
+}
+
+// Node is a mapping within a configuration document, such as a stage of the pipeline. Accessors return a default if the
+// key is absent, and an error describing the location in the document if the value is of the wrong type.
+type Node struct {
+	path    string
+	values  map[string]interface{}
+	used    map[string]bool
+	builder *builder
+}
+
+// Path returns the location of the node within the document, for use in error messages.
+func (n Node) Path() string {
+	return n.path
+}
+
+// Has reports if the key is present in the node.
+func (n Node) Has(key string) bool {
+	_, found := n.values[key]
+	return found
+}
+
+// Errorf returns an error describing a problem with the key.
+func (n Node) Errorf(key string, format string, args ...interface{}) error {
+	return fmt.Errorf("pipeline: %s.%s: %s", n.path, key, fmt.Sprintf(format, args...))
+}
+
+func (n Node) lookup(key string) (interface{}, bool) {
+	n.used[key] = true
+	value, found := n.values[key]
+	return value, found && value != nil
+}
+
+// String returns the string value of the key.
+func (n Node) String(key string, def string) (string, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return def, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", n.Errorf(key, "expected a string but found %s", describe(value))
+	}
+
+	return s, nil
+}
+
+// Strings returns the list of strings value of the key.
+func (n Node) Strings(key string) ([]string, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, n.Errorf(key, "expected a list of strings but found %s", describe(value))
+	}
+
+	strs := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, n.Errorf(fmt.Sprintf("%s[%d]", key, i), "expected a string but found %s", describe(item))
+		}
+
+		strs[i] = s
+	}
+
+	return strs, nil
+}
+
+// Int returns the integer value of the key.
+func (n Node) Int(key string, def int) (int, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+
+	return 0, n.Errorf(key, "expected an integer but found %s", describe(value))
+}
+
+// Bool returns the boolean value of the key.
+func (n Node) Bool(key string, def bool) (bool, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return def, nil
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		return false, n.Errorf(key, "expected true or false but found %s", describe(value))
+	}
+
+	return b, nil
+}
+
+// Duration returns the duration value of the key, written as understood by time.ParseDuration such as "10s".
+func (n Node) Duration(key string, def time.Duration) (time.Duration, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return def, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return 0, n.Errorf(key, "expected a duration such as \"10s\" but found %s", describe(value))
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, n.Errorf(key, "invalid duration %q, expected a duration such as \"10s\"", s)
+	}
+
+	return d, nil
+}
+
+// Level returns the log level value of the key, written as a level name such as "warn".
+func (n Node) Level(key string, def logwrap.LogLevel) (logwrap.LogLevel, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return def, nil
+	}
+
+	if s, ok := value.(string); ok {
+		for level := logwrap.Panic; level <= logwrap.Trace; level++ {
+			if strings.EqualFold(level.String(), s) {
+				return level, nil
+			}
+		}
+	}
+
+	return 0, n.Errorf(key, "expected a level (panic, fatal, error, warn, info, debug or trace) but found %s", describe(value))
+}
+
+// Node returns the mapping value of the key, for settings which are grouped together.
+func (n Node) Node(key string) (Node, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return Node{}, n.Errorf(key, "is required")
+	}
+
+	return n.builder.node(n.path+"."+key, value)
+}
+
+// Nodes returns the list of mappings value of the key.
+func (n Node) Nodes(key string) ([]Node, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, n.Errorf(key, "expected a list but found %s", describe(value))
+	}
+
+	nodes := make([]Node, len(list))
+	for i, item := range list {
+		node, err := n.builder.node(fmt.Sprintf("%s.%s[%d]", n.path, key, i), item)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = node
+	}
+
+	return nodes, nil
+}
+
+// Sink builds the stage held in the key, such as the next stage of the pipeline. The key is required.
+func (n Node) Sink(key string) (logwrap.Sink, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return nil, n.Errorf(key, "is required")
+	}
+
+	return n.builder.build(n.path+"."+key, value)
+}
+
+// Sinks builds each stage held in the list in the key.
+func (n Node) Sinks(key string) ([]logwrap.Sink, error) {
+	value, found := n.lookup(key)
+	if !found {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, n.Errorf(key, "expected a list of stages but found %s", describe(value))
+	}
+
+	sinks := make([]logwrap.Sink, len(list))
+	for i, item := range list {
+		sink, err := n.builder.build(fmt.Sprintf("%s.%s[%d]", n.path, key, i), item)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks[i] = sink
+	}
+
+	return sinks, nil
+}
+
+// checkUnused returns an error if the node has keys which were never read, as they are likely to be mistakes.
+func (n Node) checkUnused() error {
+	var unused []string
+
+	for key := range n.values {
+		if !n.used[key] {
+			unused = append(unused, key)
+		}
+	}
+
+	if len(unused) == 0 {
+		return nil
+	}
+
+	sort.Strings(unused)
+	return n.Errorf(unused[0], "unknown setting")
+}
+
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nothing"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case map[string]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	default:
+		return fmt.Sprintf("%T %v", v, v)
+	}
+}
@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("registered implementations can be referenced by name", func(t *testing.T) {
+		c := capture.NewCapture()
+		registry := NewRegistry()
+		registry.RegisterImpl("capture", c.Impl())
+
+		sink, err := Parse(registry, []byte(`type: capture`))
+		assert.NoError(t, err)
+
+		sink.Impl()(context.Background(), logwrap.Message{Message: "message"})
+		assert.Len(t, c.Messages(), 1)
+	})
+
+	t.Run("registered sinks are flushed but not closed by the pipeline", func(t *testing.T) {
+		var flushed, closed int
+
+		registry := NewRegistry()
+		registry.RegisterSink("shared", logwrap.WithLifecycle(capture.NewCapture().Impl(), func(context.Context) error {
+			flushed++
+			return nil
+		}, func(context.Context) error {
+			closed++
+			return nil
+		}))
+
+		sink, err := Parse(registry, []byte(`type: shared`))
+		assert.NoError(t, err)
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.NoError(t, sink.Close(context.Background()))
+
+		assert.Equal(t, 2, flushed)
+		assert.Equal(t, 0, closed)
+	})
+
+	t.Run("custom factories read settings and following stages from their node", func(t *testing.T) {
+		c := capture.NewCapture()
+		registry := NewRegistry()
+		registry.RegisterImpl("capture", c.Impl())
+		registry.Register("prefix", func(n Node) (logwrap.Sink, error) {
+			prefix, err := n.String("prefix", "")
+			if err != nil {
+				return nil, err
+			}
+
+			next, err := n.Sink("next")
+			if err != nil {
+				return nil, err
+			}
+
+			return logwrap.WithLifecycle(func(ctx context.Context, message logwrap.Message) {
+				message.Message = prefix + message.Message
+				next.Impl()(ctx, message)
+			}, next.Flush, next.Close), nil
+		})
+
+		sink, err := Parse(registry, []byte("type: prefix\nprefix: 'zigbee: '\nnext:\n  type: capture\n"))
+		assert.NoError(t, err)
+
+		sink.Impl()(context.Background(), logwrap.Message{Message: "message"})
+		assert.Equal(t, "zigbee: message", c.Messages()[0].Message)
+	})
+
+	t.Run("factory errors are returned and stages already built are closed", func(t *testing.T) {
+		closed := false
+
+		registry := NewRegistry()
+		registry.Register("closeable", func(Node) (logwrap.Sink, error) {
+			return logwrap.WithLifecycle(capture.NewCapture().Impl(), nil, func(context.Context) error {
+				closed = true
+				return nil
+			}), nil
+		})
+		registry.Register("failing", func(n Node) (logwrap.Sink, error) {
+			return nil, n.Errorf("setting", "failure")
+		})
+
+		_, err := Parse(registry, []byte("type: tee\ndestinations:\n  - type: closeable\n  - type: failing\n"))
+		assert.EqualError(t, err, "pipeline: pipeline.destinations[1].setting: failure")
+		assert.True(t, closed)
+	})
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"type: [":                                                    "pipeline: failed to parse document: yaml: line 1: did not find expected node content",
+		"- type: discard":                                            "pipeline: pipeline: expected a mapping but found a list",
+		"output: stdout":                                             "pipeline: pipeline.type: a stage type is required, one of: async, dedupe, discard, filter, level, redact, router, sample, tee, writer",
+		"type: missing":                                              `pipeline: pipeline.type: unknown stage type "missing", expected one of: async, dedupe, discard, filter, level, redact, router, sample, tee, writer`,
+		"type: writer\ncolor: true":                                  "pipeline: pipeline.color: unknown setting",
+		"type: writer\nformat: xml":                                  `pipeline: pipeline.format: unknown format "xml", expected one of: jsonlines, logfmt, console`,
+		"type: writer\ncolour: maybe":                                `pipeline: pipeline.colour: expected true or false but found string "maybe"`,
+		"type: level\nnext: {type: discard}":                         "pipeline: pipeline.level: is required",
+		"type: level\nlevel: loud\nnext: {type: discard}":            `pipeline: pipeline.level: expected a level (panic, fatal, error, warn, info, debug or trace) but found string "loud"`,
+		"type: level\nlevel: warn":                                   "pipeline: pipeline.next: is required",
+		"type: level\nlevel: warn\nnext: {type: nothing}":            `pipeline: pipeline.next.type: unknown stage type "nothing", expected one of: async, dedupe, discard, filter, level, redact, router, sample, tee, writer`,
+		"type: filter\nexpression: 'level <'\nnext: {type: discard}": `pipeline: pipeline.expression: filter: expected a value after "<" but found end of expression at position 7: "<end>"`,
+		"type: sample\nevery: many\nnext: {type: discard}":           `pipeline: pipeline.every: expected an integer but found string "many"`,
+		"type: sample\nnext: {type: discard}":                        "pipeline: pipeline.every: either every, first or thereafter is required",
+		"type: dedupe\nwindow: 10\nnext: {type: discard}":            `pipeline: pipeline.window: expected a duration such as "10s" but found int 10`,
+		"type: dedupe\nwindow: soon\nnext: {type: discard}":          `pipeline: pipeline.window: invalid duration "soon", expected a duration such as "10s"`,
+		"type: async\nwhenFull: panic\nnext: {type: discard}":        `pipeline: pipeline.whenFull: unknown policy "panic", expected one of: block, dropNewest, dropOldest, dropBelowLevel`,
+		"type: tee": "pipeline: pipeline.destinations: at least one destination is required",
+		"type: tee\ndestinations: {type: discard}":         "pipeline: pipeline.destinations: expected a list of stages but found a mapping",
+		"type: redact\nfields: [1]\nnext: {type: discard}": "pipeline: pipeline.fields[0]: expected a string but found int 1",
+		"type: router": "pipeline: pipeline.routes: at least one route or a default is required",
+		"type: router\nmode: some\ndefault: {type: discard}":                 `pipeline: pipeline.mode: unknown mode "some", expected first or all`,
+		"type: router\nroutes:\n  - to: {type: discard}\n    filter: x":      "pipeline: pipeline.routes[0].filter: unknown setting",
+		"type: router\nroutes:\n  - match: 'level'\n    to: {type: discard}": `pipeline: pipeline.routes[0].match: filter: expected a comparison operator after "level" but found end of expression at position 5: "<end>"`,
+		"type: writer\noutput: /nonexistent/directory/file.log":              "pipeline: pipeline.output: failed to open file: open /nonexistent/directory/file.log: no such file or directory",
+	}
+
+	for document, expected := range tests {
+		_, err := Parse(NewRegistry(), []byte(document))
+		assert.EqualError(t, err, expected, document)
+	}
+
+}
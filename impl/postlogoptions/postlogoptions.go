@@ -6,9 +6,11 @@ import (
 )
 
 // PostLogOptions is an implementation that has the ability to modify a log message before being sent onwards to another
-// implementation.
+// implementation. Options are applied to a clone of the message, so modifications never leak back to the caller.
 func PostLogOptions(impl logwrap.Impl, options ...logwrap.Option) logwrap.Impl {
 	return func(ctx context.Context, message logwrap.Message) {
+		message = message.Clone()
+
 		for _, option := range options {
 			option(&message)
 		}
@@ -16,3 +18,8 @@ func PostLogOptions(impl logwrap.Impl, options ...logwrap.Option) logwrap.Impl {
 		impl(ctx, message)
 	}
 }
+
+// PostLogOptionsSink is a Sink variant of PostLogOptions, flushing or closing it flushes or closes the destination.
+func PostLogOptionsSink(dest logwrap.Sink, options ...logwrap.Option) logwrap.Sink {
+	return logwrap.WithLifecycle(PostLogOptions(dest.Impl(), options...), dest.Flush, dest.Close)
+}
@@ -34,4 +34,47 @@ func TestPostLogOptions(t *testing.T) {
 		assert.Equal(t, expectedMessage.Message, capturedMessage.Message)
 		assert.Equal(t, expectedValue, capturedMessage.Data[expectedKey])
 	})
+
+	t.Run("post log options does not modify the callers message data", func(t *testing.T) {
+		mockImplOne := MockImpl{}
+		mockImplOne.On("Impl", mock.Anything, mock.Anything).Once()
+
+		postlog := PostLogOptions(mockImplOne.Impl, logwrap.Datum("key", "changed"))
+
+		upstreamData := map[string]interface{}{"key": "value"}
+		postlog(context.Background(), logwrap.Message{Message: "message", Data: upstreamData})
+
+		capturedMessage := mockImplOne.Calls[0].Arguments.Get(1).(logwrap.Message)
+
+		assert.Equal(t, "changed", capturedMessage.Data["key"])
+		assert.Equal(t, "value", upstreamData["key"])
+	})
+}
+
+func TestPostLogOptionsSink(t *testing.T) {
+	t.Run("post log options sink applies options and propagates flush and close to the destination", func(t *testing.T) {
+		mockImplOne := MockImpl{}
+		mockImplOne.On("Impl", mock.Anything, mock.Anything).Once()
+
+		flushed := false
+		closed := false
+
+		sink := PostLogOptionsSink(logwrap.WithLifecycle(mockImplOne.Impl, func(context.Context) error {
+			flushed = true
+			return nil
+		}, func(context.Context) error {
+			closed = true
+			return nil
+		}), logwrap.Datum("key", "value"))
+
+		sink.Impl()(context.Background(), logwrap.Message{Message: "message", Data: map[string]interface{}{}})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.NoError(t, sink.Close(context.Background()))
+
+		capturedMessage := mockImplOne.Calls[0].Arguments.Get(1).(logwrap.Message)
+		assert.Equal(t, "value", capturedMessage.Data["key"])
+		assert.True(t, flushed)
+		assert.True(t, closed)
+	})
 }
@@ -0,0 +1,303 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyFunc returns the key of the bucket a message is limited by.
+type KeyFunc func(logwrap.Message) string
+
+// BySource keys buckets by the source of the message.
+func BySource() KeyFunc {
+	return func(message logwrap.Message) string {
+		return message.Source
+	}
+}
+
+// ByMessage keys buckets by the message text.
+func ByMessage() KeyFunc {
+	return func(message logwrap.Message) string {
+		return message.Message
+	}
+}
+
+// ByField keys buckets by the value of a data field, such as a device address. Messages without the field share a
+// bucket.
+func ByField(field string) KeyFunc {
+	return func(message logwrap.Message) string {
+		if value, found := message.Data[field]; found {
+			return fmt.Sprint(value)
+		}
+
+		return ""
+	}
+}
+
+// Option is a configuration option for the rate limiting implementation.
+type Option func(*RateLimit)
+
+// DefaultRate is the default number of messages per second each bucket is refilled by.
+const DefaultRate = 10
+
+// DefaultBurst is the default capacity of each bucket.
+const DefaultBurst = 100
+
+// DefaultReportInterval is the default interval at which suppressed counts are reported.
+const DefaultReportInterval = 10 * time.Second
+
+// SuppressedField is the name of the field containing the number of suppressed messages in a report.
+const SuppressedField = "suppressed"
+
+// LimitKeyField is the name of the field containing the bucket key in a report.
+const LimitKeyField = "limitKey"
+
+// now returns the current time, it is a variable to permit testing.
+var now = time.Now
+
+// Key sets the function used to key buckets, by default BySource.
+func Key(keyFunc KeyFunc) Option {
+	return func(r *RateLimit) {
+		r.keyFunc = keyFunc
+	}
+}
+
+// DefaultBudget sets the budget used for levels which do not have their own, each bucket holds burst messages and is
+// refilled at rate messages per second.
+func DefaultBudget(rate float64, burst int) Option {
+	return func(r *RateLimit) {
+		r.defaultBudget = budget{rate: rate, burst: float64(burst)}
+	}
+}
+
+// LevelBudget sets the budget for a single level, each key has a separate bucket for each level.
+func LevelBudget(level logwrap.LogLevel, rate float64, burst int) Option {
+	return func(r *RateLimit) {
+		r.levelBudgets[level] = budget{rate: rate, burst: float64(burst)}
+	}
+}
+
+// ReportInterval sets the interval at which a Warn message is sent to the destination for each key, reporting the
+// number of messages suppressed since the last report, if any. An interval of zero disables periodic reporting,
+// suppressed counts are still reported on Flush and Close.
+func ReportInterval(interval time.Duration) Option {
+	return func(r *RateLimit) {
+		r.reportInterval = interval
+	}
+}
+
+// NewRateLimitSink initialises a new RateLimit implementation as with NewRateLimit, flushing or closing it also
+// flushes or closes the destination once suppressed counts have been reported.
+func NewRateLimitSink(dest logwrap.Sink, options ...Option) *RateLimit {
+	r := NewRateLimit(dest.Impl(), options...)
+	r.destLifecycle = dest
+
+	return r
+}
+
+// NewRateLimit initialises a new RateLimit implementation which limits the rate of messages sent to the destination
+// with a token bucket per key and level, the handle to be provided to logwrap should be obtained by calling Impl().
+//
+// Messages logged when their bucket is empty are suppressed and counted, the counts are periodically reported per key
+// as a Warn message with SuppressedField and LimitKeyField. Panic and Fatal messages are never limited. Close should be
+// called on shutdown to stop reporting.
+func NewRateLimit(dest logwrap.Impl, options ...Option) *RateLimit {
+	r := &RateLimit{
+		dest:           dest,
+		keyFunc:        BySource(),
+		defaultBudget:  budget{rate: DefaultRate, burst: DefaultBurst},
+		levelBudgets:   map[logwrap.LogLevel]budget{},
+		reportInterval: DefaultReportInterval,
+		mutex:          &sync.Mutex{},
+		buckets:        map[bucketKey]*bucket{},
+		suppressed:     map[string]uint64{},
+		done:           make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	if r.reportInterval > 0 {
+		go r.run()
+	}
+
+	return r
+}
+
+// RateLimit is a structure which provides a log implementation that limits the rate of messages per key.
+type RateLimit struct {
+	dest           logwrap.Impl
+	destLifecycle  logwrap.Lifecycle
+	keyFunc        KeyFunc
+	defaultBudget  budget
+	levelBudgets   map[logwrap.LogLevel]budget
+	reportInterval time.Duration
+
+	mutex      *sync.Mutex
+	buckets    map[bucketKey]*bucket
+	suppressed map[string]uint64
+	closed     bool
+	done       chan struct{}
+}
+
+type budget struct {
+	rate  float64
+	burst float64
+}
+
+type bucketKey struct {
+	key   string
+	level logwrap.LogLevel
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (r *RateLimit) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if message.Level == logwrap.Panic || message.Level == logwrap.Fatal || r.allow(message) {
+			r.dest(ctx, message)
+		}
+	}
+}
+
+// Suppressed returns the number of messages suppressed for each key since the last report.
+func (r *RateLimit) Suppressed() map[string]uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	suppressed := make(map[string]uint64, len(r.suppressed))
+	for key, count := range r.suppressed {
+		suppressed[key] = count
+	}
+
+	return suppressed
+}
+
+// Flush reports any suppressed counts to the destination.
+func (r *RateLimit) Flush(ctx context.Context) error {
+	r.report()
+
+	if r.destLifecycle != nil {
+		return r.destLifecycle.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Close stops periodic reporting and reports any suppressed counts to the destination. RateLimit satisfies
+// logwrap.Sink.
+func (r *RateLimit) Close(ctx context.Context) error {
+	r.mutex.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+	r.mutex.Unlock()
+
+	r.report()
+
+	if r.destLifecycle != nil {
+		return r.destLifecycle.Close(ctx)
+	}
+
+	return nil
+}
+
+// allow takes a token from the bucket for the message, counting the message as suppressed if there is none.
+func (r *RateLimit) allow(message logwrap.Message) bool {
+	b, found := r.levelBudgets[message.Level]
+	if !found {
+		b = r.defaultBudget
+	}
+
+	key := r.keyFunc(message)
+	k := bucketKey{key: key, level: message.Level}
+	current := now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	bkt, found := r.buckets[k]
+	if !found {
+		bkt = &bucket{tokens: b.burst, updated: current}
+		r.buckets[k] = bkt
+	}
+
+	bkt.tokens += current.Sub(bkt.updated).Seconds() * b.rate
+	if bkt.tokens > b.burst {
+		bkt.tokens = b.burst
+	}
+	bkt.updated = current
+
+	if bkt.tokens < 1 {
+		r.suppressed[key]++
+		return false
+	}
+
+	bkt.tokens--
+	return true
+}
+
+func (r *RateLimit) run() {
+	ticker := time.NewTicker(r.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// report sends a message to the destination for each key with messages suppressed since the last report, and discards
+// buckets which have refilled so that idle keys do not accumulate.
+func (r *RateLimit) report() {
+	r.mutex.Lock()
+
+	suppressed := r.suppressed
+	r.suppressed = map[string]uint64{}
+
+	current := now()
+
+	for k, bkt := range r.buckets {
+		b, found := r.levelBudgets[k.level]
+		if !found {
+			b = r.defaultBudget
+		}
+
+		if bkt.tokens+current.Sub(bkt.updated).Seconds()*b.rate >= b.burst {
+			delete(r.buckets, k)
+		}
+	}
+
+	r.mutex.Unlock()
+
+	keys := make([]string, 0, len(suppressed))
+	for key := range suppressed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		count := suppressed[key]
+
+		r.dest(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   fmt.Sprintf("%d messages suppressed by rate limit", count),
+			Data:      map[string]interface{}{SuppressedField: count, LimitKeyField: key},
+			Timestamp: current,
+		})
+	}
+}
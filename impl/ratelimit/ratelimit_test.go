@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("messages beyond the burst are suppressed until the bucket refills", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		c := capture.NewCapture()
+		r := NewRateLimit(c.Impl(), DefaultBudget(2, 3), ReportInterval(0))
+		impl := r.Impl()
+
+		for i := 0; i < 5; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Source: "device", Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 3)
+		assert.Equal(t, map[string]uint64{"device": 2}, r.Suppressed())
+
+		current = current.Add(time.Second)
+
+		for i := 0; i < 5; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Source: "device", Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 5)
+	})
+
+	t.Run("keys and levels have separate buckets", func(t *testing.T) {
+		c := capture.NewCapture()
+		r := NewRateLimit(c.Impl(), Key(ByField("ieee")), DefaultBudget(0, 1), LevelBudget(logwrap.Error, 0, 2), ReportInterval(0))
+		impl := r.Impl()
+
+		for i := 0; i < 3; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message", Data: map[string]interface{}{"ieee": "a"}})
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message", Data: map[string]interface{}{"ieee": "b"}})
+			impl(context.Background(), logwrap.Message{Level: logwrap.Error, Message: "message", Data: map[string]interface{}{"ieee": "a"}})
+		}
+
+		assert.Len(t, c.Messages(), 4)
+		assert.Equal(t, map[string]uint64{"a": 3, "b": 2}, r.Suppressed())
+	})
+
+	t.Run("panic and fatal messages are never limited", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := NewRateLimit(c.Impl(), DefaultBudget(0, 0), ReportInterval(0)).Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+
+		assert.Len(t, c.Messages(), 2)
+	})
+
+	t.Run("suppressed counts are reported per key and reset", func(t *testing.T) {
+		c := capture.NewCapture()
+		r := NewRateLimit(c.Impl(), Key(ByMessage()), DefaultBudget(0, 0), ReportInterval(0))
+		impl := r.Impl()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "b"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "a"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Debug, Message: "a"})
+
+		assert.NoError(t, r.Flush(context.Background()))
+
+		messages := c.Messages()
+		assert.Len(t, messages, 2)
+		assert.Equal(t, logwrap.Warn, messages[0].Level)
+		assert.Equal(t, "2 messages suppressed by rate limit", messages[0].Message)
+		assert.Equal(t, "a", messages[0].Data[LimitKeyField])
+		assert.Equal(t, uint64(2), messages[0].Data[SuppressedField])
+		assert.Equal(t, "b", messages[1].Data[LimitKeyField])
+		assert.Empty(t, r.Suppressed())
+	})
+
+	t.Run("suppressed counts are reported periodically until closed", func(t *testing.T) {
+		c := capture.NewCapture()
+		closed := false
+
+		r := NewRateLimitSink(logwrap.WithLifecycle(c.Impl(), nil, func(context.Context) error {
+			closed = true
+			return nil
+		}), DefaultBudget(0, 0), ReportInterval(5*time.Millisecond))
+
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+
+		assert.Eventually(t, func() bool {
+			return len(c.Messages()) == 1
+		}, time.Second, time.Millisecond)
+
+		assert.NoError(t, r.Close(context.Background()))
+		assert.True(t, closed)
+	})
+}
@@ -0,0 +1,227 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Option is a configuration option for the flight recorder.
+type Option func(*Recorder)
+
+// DefaultMaximumMessages is the default number of messages retained.
+const DefaultMaximumMessages = 1000
+
+// now returns the current time, it is a variable to permit testing.
+var now = time.Now
+
+// MaximumMessages sets the number of most recent messages retained, zero removes the limit.
+func MaximumMessages(count int) Option {
+	return func(r *Recorder) {
+		r.maximumMessages = count
+	}
+}
+
+// MaximumBytes sets the approximate number of bytes of most recent messages retained, zero removes the limit. The size
+// of a message is estimated from its message text, source and data rendered as JSON.
+func MaximumBytes(bytes int) Option {
+	return func(r *Recorder) {
+		r.maximumBytes = bytes
+	}
+}
+
+// MaximumAge sets the duration of most recent messages retained, measured from the time they were recorded. Zero
+// removes the limit.
+func MaximumAge(age time.Duration) Option {
+	return func(r *Recorder) {
+		r.maximumAge = age
+	}
+}
+
+// DumpOnTerminal causes the recorded messages to be dumped to the implementation provided when a Panic or Fatal message
+// is recorded, before it is passed to any later implementation.
+func DumpOnTerminal(dest logwrap.Impl) Option {
+	return func(r *Recorder) {
+		r.terminalDest = dest
+	}
+}
+
+// NewRecorder initialises a new flight recorder, which retains the most recent messages in memory so that they can be
+// dumped when something goes wrong, the handle to be provided to logwrap should be obtained by calling Impl().
+//
+// The recorder does not pass messages on, it should be placed alongside other implementations with tee and before any
+// filtering so that messages at all levels are retained. Messages are cloned as they are recorded.
+func NewRecorder(options ...Option) *Recorder {
+	r := &Recorder{
+		maximumMessages: DefaultMaximumMessages,
+		mutex:           &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Recorder is a structure which provides a log implementation that retains recent messages.
+type Recorder struct {
+	maximumMessages int
+	maximumBytes    int
+	maximumAge      time.Duration
+	terminalDest    logwrap.Impl
+
+	mutex   *sync.Mutex
+	entries []entry
+	bytes   int
+}
+
+type entry struct {
+	message  logwrap.Message
+	size     int
+	recorded time.Time
+}
+
+// Impl returns an implementation that can be passed to logwrap.
+func (r *Recorder) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		r.record(message)
+
+		if r.terminalDest != nil && (message.Level == logwrap.Panic || message.Level == logwrap.Fatal) {
+			r.Dump(ctx, r.terminalDest)
+		}
+	}
+}
+
+// Messages returns the messages currently retained, oldest first.
+func (r *Recorder) Messages() []logwrap.Message {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.expire()
+
+	messages := make([]logwrap.Message, len(r.entries))
+	for i, e := range r.entries {
+		messages[i] = e.message
+	}
+
+	return messages
+}
+
+// Clear discards all retained messages.
+func (r *Recorder) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = nil
+	r.bytes = 0
+}
+
+// Dump sends the messages currently retained to the implementation provided, oldest first. Retained messages are not
+// discarded. Panic and Fatal messages are dumped at Error level, so that dumping does not cause the destination to
+// panic or exit.
+func (r *Recorder) Dump(ctx context.Context, dest logwrap.Impl) {
+	for _, message := range r.Messages() {
+		dest(ctx, demote(message.Clone()))
+	}
+}
+
+// DumpWriter renders the messages currently retained with the formatter provided and writes them to the io.Writer,
+// oldest first. Retained messages are not discarded. The first formatting or write error is returned.
+func (r *Recorder) DumpWriter(w io.Writer, formatter format.Formatter) error {
+	for _, message := range r.Messages() {
+		data, err := formatter.Format(message)
+		if err != nil {
+			return fmt.Errorf("recorder: failed to format message: %w", err)
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("recorder: failed to write message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DumpOnSignal dumps the messages currently retained to the implementation provided each time one of the signals is
+// received, such as syscall.SIGUSR1. The function returned stops listening for the signals.
+func (r *Recorder) DumpOnSignal(dest logwrap.Impl, signals ...os.Signal) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				r.Dump(context.Background(), dest)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	once := &sync.Once{}
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+func (r *Recorder) record(message logwrap.Message) {
+	e := entry{message: message.Clone(), recorded: now()}
+
+	if r.maximumBytes > 0 {
+		e.size = len(message.Message) + len(message.Source) + len(format.JSONData(message.Data))
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, e)
+	r.bytes += e.size
+
+	for len(r.entries) > 1 && ((r.maximumMessages > 0 && len(r.entries) > r.maximumMessages) || (r.maximumBytes > 0 && r.bytes > r.maximumBytes)) {
+		r.removeOldest()
+	}
+
+	r.expire()
+}
+
+// expire discards messages older than the maximum age. Must be called with the mutex held.
+func (r *Recorder) expire() {
+	if r.maximumAge <= 0 {
+		return
+	}
+
+	cutoff := now().Add(-r.maximumAge)
+
+	for len(r.entries) > 0 && r.entries[0].recorded.Before(cutoff) {
+		r.removeOldest()
+	}
+}
+
+// removeOldest discards the oldest message. Must be called with the mutex held.
+func (r *Recorder) removeOldest() {
+	r.bytes -= r.entries[0].size
+	r.entries[0] = entry{}
+	r.entries = r.entries[1:]
+}
+
+func demote(message logwrap.Message) logwrap.Message {
+	if message.Level == logwrap.Panic || message.Level == logwrap.Fatal {
+		message.Level = logwrap.Error
+	}
+
+	return message
+}
@@ -0,0 +1,118 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func messageTexts(messages []logwrap.Message) []string {
+	var texts []string
+
+	for _, message := range messages {
+		texts = append(texts, message.Message)
+	}
+
+	return texts
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("retains the most recent messages up to the maximum count", func(t *testing.T) {
+		r := NewRecorder(MaximumMessages(2))
+
+		for _, text := range []string{"one", "two", "three"} {
+			r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: text})
+		}
+
+		assert.Equal(t, []string{"two", "three"}, messageTexts(r.Messages()))
+	})
+
+	t.Run("retains the most recent messages up to the maximum bytes", func(t *testing.T) {
+		r := NewRecorder(MaximumMessages(0), MaximumBytes(10))
+
+		for _, text := range []string{"one", "two", "three"} {
+			r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: text})
+		}
+
+		assert.Equal(t, []string{"three"}, messageTexts(r.Messages()))
+	})
+
+	t.Run("retains messages up to the maximum age", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		r := NewRecorder(MaximumAge(time.Minute))
+
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "one"})
+		current = current.Add(30 * time.Second)
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "two"})
+		current = current.Add(45 * time.Second)
+
+		assert.Equal(t, []string{"two"}, messageTexts(r.Messages()))
+	})
+
+	t.Run("dumps retained messages to an implementation without discarding them", func(t *testing.T) {
+		r := NewRecorder()
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "one"})
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "two"})
+
+		c := capture.NewCapture()
+		r.Dump(context.Background(), c.Impl())
+
+		assert.Equal(t, []string{"one", "two"}, messageTexts(c.Messages()))
+		assert.Equal(t, logwrap.Error, c.Messages()[1].Level)
+		assert.Len(t, r.Messages(), 2)
+
+		r.Clear()
+		assert.Empty(t, r.Messages())
+	})
+
+	t.Run("dumps retained messages to a writer", func(t *testing.T) {
+		r := NewRecorder()
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "one"})
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "two"})
+
+		var outputBuffer bytes.Buffer
+		err := r.DumpWriter(&outputBuffer, format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+			return []byte(message.Message + "\n"), nil
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "one\ntwo\n", outputBuffer.String())
+	})
+
+	t.Run("dumps automatically when a terminal message is recorded", func(t *testing.T) {
+		c := capture.NewCapture()
+		r := NewRecorder(DumpOnTerminal(c.Impl()))
+
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "one"})
+		assert.Empty(t, c.Messages())
+
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "two"})
+		assert.Equal(t, []string{"one", "two"}, messageTexts(c.Messages()))
+	})
+
+	t.Run("dumps on receipt of a signal until stopped", func(t *testing.T) {
+		c := capture.NewCapture()
+		r := NewRecorder()
+		r.Impl()(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "one"})
+
+		stop := r.DumpOnSignal(c.Impl(), os.Interrupt)
+		defer stop()
+
+		process, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err)
+		assert.NoError(t, process.Signal(os.Interrupt))
+
+		assert.Eventually(t, func() bool {
+			return len(c.Messages()) == 1
+		}, time.Second, time.Millisecond)
+	})
+}
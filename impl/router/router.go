@@ -0,0 +1,178 @@
+package router
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"path"
+	"strings"
+	"time"
+)
+
+// Matcher reports if a message matches a rule, filters compiled with filter.Compile may be used as a Matcher.
+type Matcher func(message logwrap.Message) bool
+
+// LevelRange matches messages with a level between mostSevere and leastSevere inclusive, such as Panic and Warn.
+func LevelRange(mostSevere logwrap.LogLevel, leastSevere logwrap.LogLevel) Matcher {
+	return func(message logwrap.Message) bool {
+		return message.Level >= mostSevere && message.Level <= leastSevere
+	}
+}
+
+// SourcePrefix matches messages whose source begins with the prefix.
+func SourcePrefix(prefix string) Matcher {
+	return func(message logwrap.Message) bool {
+		return strings.HasPrefix(message.Source, prefix)
+	}
+}
+
+// SourceGlob matches messages whose source matches the pattern, as understood by path.Match. A malformed pattern never
+// matches.
+func SourceGlob(pattern string) Matcher {
+	return func(message logwrap.Message) bool {
+		matched, err := path.Match(pattern, message.Source)
+		return err == nil && matched
+	}
+}
+
+// HasField matches messages with the data field present.
+func HasField(field string) Matcher {
+	return func(message logwrap.Message) bool {
+		_, found := message.Data[field]
+		return found
+	}
+}
+
+// Field matches messages with a data field equal to the value, values are compared as rendered by format.String so
+// that numbers of differing types compare as equal.
+func Field(field string, value interface{}) Matcher {
+	expected := format.String(value, time.RFC3339Nano)
+
+	return func(message logwrap.Message) bool {
+		actual, found := message.Data[field]
+		return found && format.String(actual, time.RFC3339Nano) == expected
+	}
+}
+
+// Option is a configuration option for the router.
+type Option func(*config)
+
+type config struct {
+	routes      []route
+	defaultDest logwrap.Sink
+	allMatches  bool
+}
+
+type route struct {
+	matchers []Matcher
+	dest     logwrap.Sink
+}
+
+// Route adds a rule sending messages matching all of the matchers to the destination, rules are evaluated in the order
+// they are added. A rule without matchers matches every message.
+func Route(dest logwrap.Impl, matchers ...Matcher) Option {
+	return RouteSink(logwrap.NoLifecycle(dest), matchers...)
+}
+
+// RouteSink is a Sink variant of Route, the destination is flushed and closed along with the router.
+func RouteSink(dest logwrap.Sink, matchers ...Matcher) Option {
+	return func(c *config) {
+		c.routes = append(c.routes, route{matchers: matchers, dest: dest})
+	}
+}
+
+// Default sets the destination of messages which match no rule, by default they are discarded.
+func Default(dest logwrap.Impl) Option {
+	return DefaultSink(logwrap.NoLifecycle(dest))
+}
+
+// DefaultSink is a Sink variant of Default, the destination is flushed and closed along with the router.
+func DefaultSink(dest logwrap.Sink) Option {
+	return func(c *config) {
+		c.defaultDest = dest
+	}
+}
+
+// AllMatches causes messages to be sent to every rule which matches, rather than only the first.
+func AllMatches() Option {
+	return func(c *config) {
+		c.allMatches = true
+	}
+}
+
+// Router is an implementation that sends messages to destinations according to an ordered list of rules, for example:
+//
+//	router.Router(
+//	    router.Route(auditImpl, router.SourcePrefix("audit")),
+//	    router.Route(alertImpl, router.LevelRange(logwrap.Panic, logwrap.Warn)),
+//	    router.Default(stdoutImpl),
+//	)
+//
+// By default a message is sent only to the first rule it matches, with AllMatches it is sent to every matching rule,
+// each destination receiving its own clone. Messages matching no rule are sent to the Default destination.
+func Router(options ...Option) logwrap.Impl {
+	return RouterSink(options...).Impl()
+}
+
+// RouterSink is a Sink variant of Router, flushing or closing it flushes or closes each destination in turn, in the
+// order they were added, with the default last. A Sink should only be given to a single rule, or it will be closed more
+// than once.
+func RouterSink(options ...Option) logwrap.Sink {
+	cfg := config{}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	impls := make([]logwrap.Impl, len(cfg.routes))
+	lifecycles := make([]logwrap.Lifecycle, 0, len(cfg.routes)+1)
+
+	for i, r := range cfg.routes {
+		impls[i] = r.dest.Impl()
+		lifecycles = append(lifecycles, r.dest)
+	}
+
+	var defaultImpl logwrap.Impl
+	if cfg.defaultDest != nil {
+		defaultImpl = cfg.defaultDest.Impl()
+		lifecycles = append(lifecycles, cfg.defaultDest)
+	}
+
+	impl := func(ctx context.Context, message logwrap.Message) {
+		matched := false
+
+		for i, r := range cfg.routes {
+			if !matches(r.matchers, message) {
+				continue
+			}
+
+			if !cfg.allMatches {
+				impls[i](ctx, message)
+				return
+			}
+
+			matched = true
+			impls[i](ctx, message.Clone())
+		}
+
+		if !matched && defaultImpl != nil {
+			defaultImpl(ctx, message)
+		}
+	}
+
+	return logwrap.WithLifecycle(impl, func(ctx context.Context) error {
+		return logwrap.FlushAll(ctx, lifecycles...)
+	}, func(ctx context.Context) error {
+		return logwrap.CloseAll(ctx, lifecycles...)
+	})
+}
+
+func matches(matchers []Matcher, message logwrap.Message) bool {
+	for _, matcher := range matchers {
+		if !matcher(message) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/shimmeringbee/logwrap/impl/filter"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	t.Run("messages are sent to the first matching rule or the default", func(t *testing.T) {
+		audit := capture.NewCapture()
+		alert := capture.NewCapture()
+		stdout := capture.NewCapture()
+
+		impl := Router(
+			Route(audit.Impl(), SourcePrefix("audit")),
+			Route(alert.Impl(), LevelRange(logwrap.Panic, logwrap.Warn)),
+			Default(stdout.Impl()),
+		)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error, Source: "audit.login", Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Source: "network", Message: "two"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Source: "network", Message: "three"})
+
+		assert.Len(t, audit.Messages(), 1)
+		assert.Equal(t, "one", audit.Messages()[0].Message)
+		assert.Len(t, alert.Messages(), 1)
+		assert.Equal(t, "two", alert.Messages()[0].Message)
+		assert.Len(t, stdout.Messages(), 1)
+		assert.Equal(t, "three", stdout.Messages()[0].Message)
+	})
+
+	t.Run("all matching rules receive the message when configured", func(t *testing.T) {
+		audit := capture.NewCapture()
+		alert := capture.NewCapture()
+		stdout := capture.NewCapture()
+
+		impl := Router(
+			AllMatches(),
+			Route(audit.Impl(), SourcePrefix("audit")),
+			Route(alert.Impl(), LevelRange(logwrap.Panic, logwrap.Warn)),
+			Default(stdout.Impl()),
+		)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error, Source: "audit.login", Data: map[string]interface{}{"key": "value"}})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Source: "network"})
+
+		assert.Len(t, audit.Messages(), 1)
+		assert.Len(t, alert.Messages(), 1)
+		assert.Len(t, stdout.Messages(), 1)
+
+		audit.Messages()[0].Data["key"] = "modified"
+		assert.Equal(t, "value", alert.Messages()[0].Data["key"])
+	})
+
+	t.Run("messages matching no rule are discarded without a default", func(t *testing.T) {
+		audit := capture.NewCapture()
+		impl := Router(Route(audit.Impl(), SourcePrefix("audit")))
+
+		impl(context.Background(), logwrap.Message{Source: "network"})
+
+		assert.Empty(t, audit.Messages())
+	})
+
+	t.Run("rules require all matchers to match", func(t *testing.T) {
+		dest := capture.NewCapture()
+		impl := Router(Route(dest.Impl(), SourceGlob("zigbee.*"), HasField("ieee"), Field("retries", uint64(3))))
+
+		impl(context.Background(), logwrap.Message{Source: "zigbee.network", Data: map[string]interface{}{"ieee": "a", "retries": 3}})
+		impl(context.Background(), logwrap.Message{Source: "zigbee.network", Data: map[string]interface{}{"retries": 3}})
+		impl(context.Background(), logwrap.Message{Source: "zigbee.network", Data: map[string]interface{}{"ieee": "a", "retries": 4}})
+		impl(context.Background(), logwrap.Message{Source: "zwave.network", Data: map[string]interface{}{"ieee": "a", "retries": 3}})
+
+		assert.Len(t, dest.Messages(), 1)
+	})
+
+	t.Run("compiled filter expressions may be used as matchers", func(t *testing.T) {
+		dest := capture.NewCapture()
+		impl := Router(Route(dest.Impl(), filter.MustCompile(`level <= warn`)))
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Error})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info})
+
+		assert.Len(t, dest.Messages(), 1)
+	})
+}
+
+func TestRouterSink(t *testing.T) {
+	t.Run("flushes and closes each destination including the default", func(t *testing.T) {
+		var flushed, closed []string
+
+		sink := func(name string) logwrap.Sink {
+			return logwrap.WithLifecycle(capture.NewCapture().Impl(), func(context.Context) error {
+				flushed = append(flushed, name)
+				return nil
+			}, func(context.Context) error {
+				closed = append(closed, name)
+				return nil
+			})
+		}
+
+		r := RouterSink(RouteSink(sink("one")), DefaultSink(sink("default")), RouteSink(sink("two")))
+
+		assert.NoError(t, r.Flush(context.Background()))
+		assert.NoError(t, r.Close(context.Background()))
+
+		assert.Equal(t, []string{"one", "two", "default"}, flushed)
+		assert.Equal(t, []string{"one", "two", "default"}, closed)
+	})
+}
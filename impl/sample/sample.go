@@ -0,0 +1,123 @@
+package sample
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+	"time"
+)
+
+// SampleRateField is the name of the field the sampling rate is recorded in on emitted messages, a rate of N denotes
+// that the message represents N messages, permitting counts to be extrapolated.
+const SampleRateField = "sampleRate"
+
+// DefaultThreshold is the default level at or above which messages are never sampled.
+const DefaultThreshold = logwrap.Warn
+
+// now returns the current time, it is a variable to permit testing.
+var now = time.Now
+
+// maximumKeys is the number of distinct keys tracked before counts are reset, to bound memory use.
+const maximumKeys = 4096
+
+// Option is a configuration option for the sampling implementation.
+type Option func(*config)
+
+type config struct {
+	first      uint64
+	thereafter uint64
+	interval   time.Duration
+	threshold  logwrap.LogLevel
+}
+
+// Every keeps one in every n messages with the same level and message text, starting with the first. An n of zero keeps
+// only the first message.
+func Every(n uint64) Option {
+	return func(c *config) {
+		c.first = 1
+		c.thereafter = n
+		c.interval = 0
+	}
+}
+
+// FirstThenEvery keeps the first messages with the same level and message text within each interval, and thereafter
+// one in every thereafter messages. A thereafter of zero drops all messages after the first within each interval.
+func FirstThenEvery(first uint64, thereafter uint64, interval time.Duration) Option {
+	return func(c *config) {
+		c.first = first
+		c.thereafter = thereafter
+		c.interval = interval
+	}
+}
+
+// Threshold sets the level at or above which messages are never sampled, by default Warn.
+func Threshold(level logwrap.LogLevel) Option {
+	return func(c *config) {
+		c.threshold = level
+	}
+}
+
+type key struct {
+	level   logwrap.LogLevel
+	message string
+}
+
+// Sample is an implementation that samples messages before sending them onwards to another implementation, reducing the
+// volume of high rate messages. Messages are grouped by their level and message text, which should be a fixed template
+// rather than contain variable data. Emitted messages which have been sampled have the rate recorded in
+// SampleRateField. If no sampling option is provided, all messages are kept.
+func Sample(impl logwrap.Impl, options ...Option) logwrap.Impl {
+	cfg := config{
+		first:      1,
+		thereafter: 1,
+		threshold:  DefaultThreshold,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	mutex := &sync.Mutex{}
+	counts := map[key]uint64{}
+	var windowStart time.Time
+
+	return func(ctx context.Context, message logwrap.Message) {
+		if message.Level <= cfg.threshold {
+			impl(ctx, message)
+			return
+		}
+
+		mutex.Lock()
+
+		current := now()
+		if (cfg.interval > 0 && current.Sub(windowStart) >= cfg.interval) || len(counts) >= maximumKeys {
+			counts = map[key]uint64{}
+			windowStart = current
+		}
+
+		k := key{level: message.Level, message: message.Message}
+		counts[k]++
+		count := counts[k]
+
+		mutex.Unlock()
+
+		if count <= cfg.first {
+			impl(ctx, message)
+			return
+		}
+
+		if cfg.thereafter == 0 || (count-cfg.first)%cfg.thereafter != 0 {
+			return
+		}
+
+		if cfg.thereafter > 1 {
+			message = message.Clone()
+			if message.Data == nil {
+				message.Data = map[string]interface{}{}
+			}
+			message.Data[SampleRateField] = cfg.thereafter
+		}
+
+		impl(ctx, message)
+	}
+}
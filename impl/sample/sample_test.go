@@ -0,0 +1,107 @@
+package sample
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSample(t *testing.T) {
+	t.Run("all messages are kept if no sampling option is provided", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := Sample(c.Impl())
+
+		for i := 0; i < 5; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 5)
+		assert.NotContains(t, c.Messages()[4].Data, SampleRateField)
+	})
+
+	t.Run("every keeps one in n messages per level and message, recording the rate", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := Sample(c.Impl(), Every(3))
+
+		for i := 0; i < 7; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+		}
+		impl(context.Background(), logwrap.Message{Level: logwrap.Debug, Message: "one"})
+
+		messages := c.Messages()
+		assert.Len(t, messages, 4)
+		assert.NotContains(t, messages[0].Data, SampleRateField)
+		assert.Equal(t, uint64(3), messages[1].Data[SampleRateField])
+		assert.Equal(t, uint64(3), messages[2].Data[SampleRateField])
+		assert.Equal(t, logwrap.Debug, messages[3].Level)
+	})
+
+	t.Run("first then every resets counts at the start of each interval", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		c := capture.NewCapture()
+		impl := Sample(c.Impl(), FirstThenEvery(2, 5, time.Second))
+
+		for i := 0; i < 12; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 4)
+
+		current = current.Add(time.Second)
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+
+		assert.Len(t, c.Messages(), 5)
+		assert.NotContains(t, c.Messages()[4].Data, SampleRateField)
+	})
+
+	t.Run("a thereafter of zero drops all messages after the first within each interval", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		current := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		c := capture.NewCapture()
+		impl := Sample(c.Impl(), FirstThenEvery(10, 0, time.Second))
+
+		for i := 0; i < 20; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 10)
+
+		current = current.Add(time.Second)
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "message"})
+
+		assert.Len(t, c.Messages(), 11)
+	})
+
+	t.Run("messages at or above the threshold are never sampled", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := Sample(c.Impl(), Every(10))
+
+		for i := 0; i < 3; i++ {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message"})
+			impl(context.Background(), logwrap.Message{Level: logwrap.Error, Message: "message"})
+		}
+
+		assert.Len(t, c.Messages(), 6)
+	})
+
+	t.Run("the callers data is not modified when the rate is recorded", func(t *testing.T) {
+		c := capture.NewCapture()
+		impl := Sample(c.Impl(), Every(2), Threshold(logwrap.Error))
+
+		data := map[string]interface{}{"key": "value"}
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: data})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: data})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Warn, Message: "message", Data: data})
+
+		assert.Len(t, c.Messages(), 2)
+		assert.NotContains(t, data, SampleRateField)
+	})
+}
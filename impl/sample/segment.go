@@ -0,0 +1,133 @@
+package sample
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+)
+
+// DefaultForceKeepLevel is the default level at or above which a message forces its segment to be kept.
+const DefaultForceKeepLevel = logwrap.Error
+
+// SegmentOption is a configuration option for the segment sampling implementation.
+type SegmentOption func(*segmentConfig)
+
+type segmentConfig struct {
+	forceKeepLevel logwrap.LogLevel
+}
+
+// ForceKeepLevel sets the level at or above which a message logged inside a dropped segment causes the rest of that
+// segment to be kept, by default Error.
+func ForceKeepLevel(level logwrap.LogLevel) SegmentOption {
+	return func(c *segmentConfig) {
+		c.forceKeepLevel = level
+	}
+}
+
+// Segments is an implementation that samples whole segments, keeping one in every n root segments along with all of
+// their descendants, so that segment trees are never broken apart. The decision is made deterministically from the root
+// segment ID. Messages which are not part of a segment are always passed on, and may be sampled with Sample.
+//
+// Once a message at or above the ForceKeepLevel is logged inside a dropped segment, it and the remainder of the root
+// segment are kept. Messages dropped before then are not recovered. Emitted messages from sampled segments have the rate
+// recorded in SampleRateField.
+func Segments(impl logwrap.Impl, n uint64, options ...SegmentOption) logwrap.Impl {
+	cfg := segmentConfig{
+		forceKeepLevel: DefaultForceKeepLevel,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	if n == 0 {
+		n = 1
+	}
+
+	s := &segmentSampler{
+		rate:   n,
+		roots:  map[uint64]uint64{},
+		forced: map[uint64]bool{},
+	}
+
+	return func(ctx context.Context, message logwrap.Message) {
+		segmentID, ok := message.Data[logwrap.SegmentIDField].(uint64)
+		if !ok {
+			impl(ctx, message)
+			return
+		}
+
+		parentID, hasParent := message.Data[logwrap.ParentSegmentIDField].(uint64)
+
+		keep, forced := s.decide(segmentID, parentID, hasParent, message, cfg.forceKeepLevel)
+		if !keep {
+			return
+		}
+
+		if !forced && n > 1 {
+			message = message.Clone()
+			message.Data[SampleRateField] = n
+		}
+
+		impl(ctx, message)
+	}
+}
+
+type segmentSampler struct {
+	rate   uint64
+	mutex  sync.Mutex
+	roots  map[uint64]uint64
+	forced map[uint64]bool
+}
+
+// decide determines if a message belonging to a segment should be kept, and if it was kept only because the segment has
+// been forced, tracking the root of each open segment.
+func (s *segmentSampler) decide(segmentID uint64, parentID uint64, hasParent bool, message logwrap.Message, forceKeepLevel logwrap.LogLevel) (bool, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	root, known := s.roots[segmentID]
+	if !known {
+		root = segmentID
+
+		if hasParent {
+			if parentRoot, found := s.roots[parentID]; found {
+				root = parentRoot
+			} else {
+				root = parentID
+			}
+		}
+
+		if message.Data[logwrap.SegmentField] == logwrap.SegmentStartValue {
+			s.roots[segmentID] = root
+		}
+	}
+
+	if message.Data[logwrap.SegmentField] == logwrap.SegmentEndValue {
+		delete(s.roots, segmentID)
+	}
+
+	sampled := mix(root)%s.rate == 0
+
+	if !sampled && message.Level <= forceKeepLevel {
+		s.forced[root] = true
+	}
+
+	forced := s.forced[root]
+
+	if segmentID == root && message.Data[logwrap.SegmentField] == logwrap.SegmentEndValue {
+		delete(s.forced, root)
+	}
+
+	return sampled || forced, forced
+}
+
+// mix scrambles a segment ID, so that sequential IDs are spread evenly when reduced by the sampling rate.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
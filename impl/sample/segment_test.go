@@ -0,0 +1,90 @@
+package sample
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	t.Run("whole segment trees are kept or dropped based on the root segment", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(Segments(c.Impl(), 3))
+
+		expectedRoots := map[uint64]bool{}
+
+		for i := 0; i < 30; i++ {
+			ctx, end := logger.Segment(context.Background(), "root")
+			rootID := uint64(2*i + 1)
+
+			subCtx, subEnd := logger.Segment(ctx, "child")
+			logger.Info(subCtx, "detail")
+			subEnd()
+			end()
+
+			if mix(rootID)%3 == 0 {
+				expectedRoots[rootID] = true
+			}
+		}
+
+		assert.NotEmpty(t, expectedRoots)
+		assert.Len(t, c.Messages(), len(expectedRoots)*5)
+
+		for _, message := range c.Messages() {
+			rootID := message.Data[logwrap.SegmentIDField].(uint64)
+			if parentID, ok := message.Data[logwrap.ParentSegmentIDField].(uint64); ok {
+				rootID = parentID
+			}
+
+			assert.True(t, expectedRoots[rootID])
+			assert.Equal(t, uint64(3), message.Data[SampleRateField])
+		}
+	})
+
+	t.Run("messages outside of segments are always kept", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(Segments(c.Impl(), 1000))
+
+		logger.Info(context.Background(), "message")
+
+		assert.Len(t, c.Messages(), 1)
+	})
+
+	t.Run("an error inside a dropped segment forces the remainder of the segment to be kept", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := logwrap.New(Segments(c.Impl(), 2))
+
+		var droppedID uint64
+
+		for id := uint64(1); droppedID == 0; id++ {
+			ctx, end := logger.Segment(context.Background(), "root")
+			if mix(id)%2 != 0 {
+				droppedID = id
+				c.Clear()
+
+				logger.Debug(ctx, "before")
+				logger.Error(ctx, "failure")
+				logger.Debug(ctx, "after")
+			}
+			end()
+		}
+
+		messages := c.Messages()
+		assert.Len(t, messages, 3)
+		assert.Equal(t, "failure", messages[0].Message)
+		assert.Equal(t, "after", messages[1].Message)
+		assert.Equal(t, logwrap.SegmentEndValue, messages[2].Data[logwrap.SegmentField])
+		assert.NotContains(t, messages[0].Data, SampleRateField)
+
+		ctx, end := logger.Segment(context.Background(), "next")
+		defer end()
+		c.Clear()
+
+		logger.Info(ctx, "unrelated")
+		if mix(droppedID+1)%2 != 0 {
+			assert.Empty(t, c.Messages())
+		}
+	})
+}
@@ -0,0 +1,162 @@
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	realSlog "log/slog"
+	"runtime"
+)
+
+// HandlerOption is a configuration option for Handler.
+type HandlerOption func(*Handler)
+
+// MinimumLevel sets the minimum slog level the Handler reports as enabled, by default all levels are enabled.
+func MinimumLevel(level realSlog.Leveler) HandlerOption {
+	return func(h *Handler) {
+		h.level = level
+	}
+}
+
+// Handler is a slog.Handler which feeds slog records into a logwrap Logger, allowing slog and logwrap to share one
+// output pipeline. It should be constructed with NewHandler or NewLoggerHandler.
+type Handler struct {
+	logger logwrap.Logger
+	level  realSlog.Leveler
+	prefix string
+	attrs  []logwrap.Option
+}
+
+// NewHandler constructs a Handler which sends records to a logwrap implementation.
+func NewHandler(impl logwrap.Impl, options ...HandlerOption) *Handler {
+	return NewLoggerHandler(logwrap.New(impl), options...)
+}
+
+// NewLoggerHandler constructs a Handler which logs records through a logwrap Logger, as such any options on the logger
+// or within the context are applied to each record.
+//
+// Record levels are mapped onto the nearest logwrap level at or below them, levels above slog.LevelError are mapped to
+// Error so that slog records never panic or exit the application. The record time is preserved, a string attribute
+// named SourceKey is used as the message source, and the records program counter if present is added as a
+// logwrap.SourceLocation under logwrap.SourceTraceField. Attributes within groups are flattened into period delimited
+// keys.
+func NewLoggerHandler(logger logwrap.Logger, options ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+		level:  LevelTrace,
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// Enabled reports if the level is at or above the handlers minimum level.
+func (h *Handler) Enabled(_ context.Context, level realSlog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle logs the record through the logwrap Logger, a zero record time is ignored as required by slog.Handler.
+func (h *Handler) Handle(ctx context.Context, record realSlog.Record) error {
+	options := make([]logwrap.Option, 0, len(h.attrs)+record.NumAttrs()+2)
+	options = append(options, h.attrs...)
+
+	record.Attrs(func(attr realSlog.Attr) bool {
+		options = appendAttr(options, h.prefix, attr)
+		return true
+	})
+
+	options = append(options, logwrap.Level(mapSlogLevels(record.Level)))
+
+	if !record.Time.IsZero() {
+		options = append(options, func(message *logwrap.Message) {
+			message.Timestamp = record.Time
+		})
+	}
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		options = append(options, logwrap.Datum(logwrap.SourceTraceField, logwrap.SourceLocation{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		}))
+	}
+
+	h.logger.Log(ctx, record.Message, options...)
+	return nil
+}
+
+// WithAttrs returns a new Handler which includes the attributes on every record.
+func (h *Handler) WithAttrs(attrs []realSlog.Attr) realSlog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := *h
+	clone.attrs = append([]logwrap.Option{}, h.attrs...)
+
+	for _, attr := range attrs {
+		clone.attrs = appendAttr(clone.attrs, h.prefix, attr)
+	}
+
+	return &clone
+}
+
+// WithGroup returns a new Handler which places all following attributes within the named group.
+func (h *Handler) WithGroup(name string) realSlog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+
+	return &clone
+}
+
+func appendAttr(options []logwrap.Option, prefix string, attr realSlog.Attr) []logwrap.Option {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(realSlog.Attr{}) {
+		return options
+	}
+
+	if attr.Value.Kind() == realSlog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+
+		for _, groupAttr := range attr.Value.Group() {
+			options = appendAttr(options, groupPrefix, groupAttr)
+		}
+
+		return options
+	}
+
+	if prefix == "" && attr.Key == SourceKey && attr.Value.Kind() == realSlog.KindString {
+		return append(options, logwrap.Source(attr.Value.String()))
+	}
+
+	return append(options, logwrap.Datum(prefix+attr.Key, attr.Value.Any()))
+}
+
+func mapSlogLevels(level realSlog.Level) logwrap.LogLevel {
+	switch {
+	case level >= realSlog.LevelError:
+		return logwrap.Error
+	case level >= realSlog.LevelWarn:
+		return logwrap.Warn
+	case level >= realSlog.LevelInfo:
+		return logwrap.Info
+	case level >= realSlog.LevelDebug:
+		return logwrap.Debug
+	default:
+		return logwrap.Trace
+	}
+}
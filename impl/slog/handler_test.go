@@ -0,0 +1,109 @@
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	realSlog "log/slog"
+	"testing"
+	"time"
+)
+
+func Test_mapSlogLevels(t *testing.T) {
+	t.Run("maps slog to logwrap log levels, never above error", func(t *testing.T) {
+		assert.Equal(t, logwrap.Error, mapSlogLevels(LevelPanic))
+		assert.Equal(t, logwrap.Error, mapSlogLevels(realSlog.LevelError))
+		assert.Equal(t, logwrap.Warn, mapSlogLevels(realSlog.LevelWarn))
+		assert.Equal(t, logwrap.Info, mapSlogLevels(realSlog.LevelInfo))
+		assert.Equal(t, logwrap.Info, mapSlogLevels(realSlog.LevelInfo+1))
+		assert.Equal(t, logwrap.Debug, mapSlogLevels(realSlog.LevelDebug))
+		assert.Equal(t, logwrap.Trace, mapSlogLevels(LevelTrace))
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("handler sends records to the implementation with message, level, time, source and attributes", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realSlog.New(NewHandler(c.Impl()))
+
+		logger.Warn("message", SourceKey, "source", "key", "value")
+
+		m := c.Messages()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, logwrap.Warn, m[0].Level)
+		assert.Equal(t, "message", m[0].Message)
+		assert.Equal(t, "source", m[0].Source)
+		assert.Equal(t, "value", m[0].Data["key"])
+		assert.WithinDuration(t, time.Now(), m[0].Timestamp, time.Second)
+
+		location, ok := m[0].Data[logwrap.SourceTraceField].(logwrap.SourceLocation)
+		assert.True(t, ok)
+		assert.Contains(t, location.File, "handler_test.go")
+	})
+
+	t.Run("handler preserves the record time", func(t *testing.T) {
+		c := capture.NewCapture()
+		handler := NewHandler(c.Impl())
+
+		expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		assert.NoError(t, handler.Handle(context.Background(), realSlog.NewRecord(expectedTime, realSlog.LevelInfo, "message", 0)))
+
+		assert.Equal(t, expectedTime, c.Messages()[0].Timestamp)
+	})
+
+	t.Run("handler ignores a zero record time, keeping the logger timestamp", func(t *testing.T) {
+		c := capture.NewCapture()
+		handler := NewHandler(c.Impl())
+
+		assert.NoError(t, handler.Handle(context.Background(), realSlog.NewRecord(time.Time{}, realSlog.LevelInfo, "message", 0)))
+
+		assert.WithinDuration(t, time.Now(), c.Messages()[0].Timestamp, time.Second)
+	})
+
+	t.Run("handler flattens groups and attributes added with WithAttrs and WithGroup", func(t *testing.T) {
+		c := capture.NewCapture()
+		logger := realSlog.New(NewHandler(c.Impl())).With("a", 1).WithGroup("g").With("b", 2)
+
+		logger.Info("message", "c", 3, realSlog.Group("h", "d", 4))
+
+		data := c.Messages()[0].Data
+		assert.Equal(t, int64(1), data["a"])
+		assert.Equal(t, int64(2), data["g.b"])
+		assert.Equal(t, int64(3), data["g.c"])
+		assert.Equal(t, int64(4), data["g.h.d"])
+	})
+
+	t.Run("handler logs through a logger, applying its options", func(t *testing.T) {
+		c := capture.NewCapture()
+		logwrapLogger := logwrap.New(c.Impl())
+		logwrapLogger.AddOptionsToLogger(logwrap.Datum("logger", "option"))
+
+		realSlog.New(NewLoggerHandler(logwrapLogger)).Info("message")
+
+		assert.Equal(t, "option", c.Messages()[0].Data["logger"])
+	})
+
+	t.Run("handler only enables levels at or above the minimum level", func(t *testing.T) {
+		handler := NewHandler(nil, MinimumLevel(realSlog.LevelWarn))
+
+		assert.False(t, handler.Enabled(context.Background(), realSlog.LevelInfo))
+		assert.True(t, handler.Enabled(context.Background(), realSlog.LevelWarn))
+	})
+
+	t.Run("records sent through wrap and handler share one pipeline", func(t *testing.T) {
+		c := capture.NewCapture()
+		logwrapLogger := logwrap.New(Wrap(NewHandler(c.Impl())))
+
+		logwrapLogger.Debug(context.Background(), "message", logwrap.Source("source"), logwrap.Datum("key", "value"))
+
+		m := c.Messages()[0]
+		assert.Equal(t, logwrap.Debug, m.Level)
+		assert.Equal(t, "source", m.Source)
+		assert.Equal(t, "value", m.Data["key"])
+	})
+}
@@ -0,0 +1,111 @@
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	realSlog "log/slog"
+	"os"
+)
+
+// SourceKey is the attribute key the message source is written under, and read from by Handler.
+const SourceKey = "source"
+
+// SequenceKey is the attribute key the message sequence is written under.
+const SequenceKey = "sequence"
+
+// SegmentKey is the name of the group segment fields are written under.
+const SegmentKey = "segment"
+
+// Levels used for messages which have no direct equivalent in log/slog.
+const (
+	LevelPanic = realSlog.LevelError + 8
+	LevelFatal = realSlog.LevelError + 4
+	LevelTrace = realSlog.LevelDebug - 4
+)
+
+// exit is called on receipt of a Fatal message, it is a variable to permit testing.
+var exit = os.Exit
+
+// Wrap implements a log/slog wrapper, allowing the output from logwrap to be sent to any slog.Handler.
+//
+// Levels are mapped onto their slog equivalents, with Panic, Fatal and Trace mapped onto LevelPanic, LevelFatal and
+// LevelTrace. The message source and sequence are added as attributes, segment fields are grouped under SegmentKey and
+// all other data is added as attributes in key order. As slog does not panic or exit, Wrap does so once the handler has
+// been called for Panic and Fatal messages. Errors returned by the handler are reported with logwrap.ReportError.
+func Wrap(handler realSlog.Handler) logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		level := mapLogLevels(message.Level)
+
+		if handler.Enabled(ctx, level) {
+			if err := handler.Handle(ctx, buildRecord(message, level)); err != nil {
+				logwrap.ReportError(ctx, fmt.Errorf("slog: failed to handle record: %w", err))
+			}
+		}
+
+		switch message.Level {
+		case logwrap.Panic:
+			panic(message.Message)
+		case logwrap.Fatal:
+			exit(-1)
+		}
+	}
+}
+
+func buildRecord(message logwrap.Message, level realSlog.Level) realSlog.Record {
+	record := realSlog.NewRecord(message.Timestamp, level, message.Message, 0)
+
+	if message.Source != "" {
+		record.AddAttrs(realSlog.String(SourceKey, message.Source))
+	}
+
+	record.AddAttrs(realSlog.Uint64(SequenceKey, message.Sequence))
+
+	var segmentAttrs []interface{}
+
+	for _, key := range format.SortedKeys(message.Data) {
+		value := message.Data[key]
+
+		switch key {
+		case logwrap.SegmentField:
+			segmentAttrs = append(segmentAttrs, realSlog.Any("marker", value))
+		case logwrap.SegmentIDField:
+			segmentAttrs = append(segmentAttrs, realSlog.Any("id", value))
+		case logwrap.ParentSegmentIDField:
+			segmentAttrs = append(segmentAttrs, realSlog.Any("parentID", value))
+		default:
+			record.AddAttrs(realSlog.Any(key, value))
+		}
+	}
+
+	if len(segmentAttrs) > 0 {
+		record.AddAttrs(realSlog.Group(SegmentKey, segmentAttrs...))
+	}
+
+	return record
+}
+
+func mapLogLevels(level logwrap.LogLevel) realSlog.Level {
+	switch level {
+	case logwrap.Panic:
+		return LevelPanic
+	case logwrap.Fatal:
+		return LevelFatal
+	case logwrap.Error:
+		return realSlog.LevelError
+	case logwrap.Warn:
+		return realSlog.LevelWarn
+	case logwrap.Info:
+		return realSlog.LevelInfo
+	case logwrap.Debug:
+		return realSlog.LevelDebug
+	case logwrap.Trace:
+		return LevelTrace
+	default:
+		return realSlog.LevelInfo
+	}
+}
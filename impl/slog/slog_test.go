@@ -0,0 +1,125 @@
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	realSlog "log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// failingHandler is a slog.Handler which enables all levels and fails to handle every record.
+type failingHandler struct {
+	realSlog.Handler
+}
+
+func (failingHandler) Enabled(context.Context, realSlog.Level) bool {
+	return true
+}
+
+func (failingHandler) Handle(context.Context, realSlog.Record) error {
+	return errors.New("failure")
+}
+
+func Test_mapLogLevels(t *testing.T) {
+	t.Run("maps logwrap to slog log levels", func(t *testing.T) {
+		assert.Equal(t, LevelPanic, mapLogLevels(logwrap.Panic))
+		assert.Equal(t, LevelFatal, mapLogLevels(logwrap.Fatal))
+		assert.Equal(t, realSlog.LevelError, mapLogLevels(logwrap.Error))
+		assert.Equal(t, realSlog.LevelWarn, mapLogLevels(logwrap.Warn))
+		assert.Equal(t, realSlog.LevelInfo, mapLogLevels(logwrap.Info))
+		assert.Equal(t, realSlog.LevelDebug, mapLogLevels(logwrap.Debug))
+		assert.Equal(t, LevelTrace, mapLogLevels(logwrap.Trace))
+
+		// Unknown logwrap level.
+		assert.Equal(t, realSlog.LevelInfo, mapLogLevels(logwrap.LogLevel(math.MaxUint64)))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("wrap sends log with message, level, time, source, sequence, segment and fields", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		handler := realSlog.NewJSONHandler(&outputBuffer, &realSlog.HandlerOptions{Level: LevelTrace})
+
+		expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+		Wrap(handler)(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Timestamp: expectedTime,
+			Sequence:  5,
+			Source:    "source",
+			Data: map[string]interface{}{
+				"key":                        "value",
+				logwrap.SegmentField:         logwrap.SegmentStartValue,
+				logwrap.SegmentIDField:       uint64(2),
+				logwrap.ParentSegmentIDField: uint64(1),
+			},
+		})
+
+		decoded := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &decoded))
+
+		assert.Equal(t, "2020-06-01T12:30:00Z", decoded["time"])
+		assert.Equal(t, "WARN", decoded["level"])
+		assert.Equal(t, "message", decoded["msg"])
+		assert.Equal(t, "source", decoded[SourceKey])
+		assert.Equal(t, float64(5), decoded[SequenceKey])
+		assert.Equal(t, "value", decoded["key"])
+		assert.Equal(t, map[string]interface{}{"marker": "start", "id": float64(2), "parentID": float64(1)}, decoded[SegmentKey])
+	})
+
+	t.Run("wrap does not send messages at levels the handler does not enable", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		handler := realSlog.NewJSONHandler(&outputBuffer, &realSlog.HandlerOptions{Level: realSlog.LevelInfo})
+
+		Wrap(handler)(context.Background(), logwrap.Message{Level: logwrap.Debug, Message: "message"})
+
+		assert.Empty(t, outputBuffer.String())
+	})
+
+	t.Run("wrap panics after handling panic level messages", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		handler := realSlog.NewJSONHandler(&outputBuffer, nil)
+
+		assert.Panics(t, func() {
+			Wrap(handler)(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+
+	t.Run("wrap exits after handling fatal level messages", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		handler := realSlog.NewJSONHandler(&outputBuffer, nil)
+
+		exitCode := 0
+		exit = func(code int) { exitCode = code }
+		defer func() { exit = os.Exit }()
+
+		Wrap(handler)(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.Equal(t, -1, exitCode)
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+
+	t.Run("wrap reports errors returned by the handler", func(t *testing.T) {
+		var reported error
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported = err
+		})
+
+		Wrap(failingHandler{})(ctx, logwrap.Message{Level: logwrap.Info, Message: "message"})
+
+		assert.EqualError(t, reported, "slog: failed to handle record: failure")
+	})
+}
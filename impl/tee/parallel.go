@@ -0,0 +1,204 @@
+package tee
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"sync"
+	"time"
+)
+
+// ParallelTee is an implementation that distributes log messages to multiple different implementations concurrently,
+// isolating each destination from the others and from the caller.
+//
+// Each destination is called on its own go routine with its own clone of the message, so a destination which modifies
+// the data can not affect its siblings. Panics within a destination are recovered and reported with
+// logwrap.ReportError. The caller waits for all destinations to return, or for the timeout to pass at which point any
+// destination still running is reported and left to complete in the background. A timeout of zero waits indefinitely.
+//
+// Once a destination has failed to complete within the timeout, further messages for it are dropped and reported
+// until the late delivery returns, rather than accumulating behind it.
+//
+// Panic and Fatal messages are delivered to one destination at a time in the order given, waiting for each to return
+// or time out before the next. A destination which exits the process prevents delivery to those after it, so such
+// destinations should be placed last. Panics caused by a Panic message are expected and not reported, ParallelTee
+// panics once all destinations have returned.
+func ParallelTee(timeout time.Duration, destinations ...logwrap.Impl) logwrap.Impl {
+	p := &parallelTee{
+		timeout:      timeout,
+		destinations: destinations,
+		mutex:        &sync.Mutex{},
+		stuck:        make([]int, len(destinations)),
+	}
+
+	return func(ctx context.Context, message logwrap.Message) {
+		if message.Level == logwrap.Panic || message.Level == logwrap.Fatal {
+			p.deliverInTurn(ctx, message)
+		} else {
+			p.deliverConcurrently(ctx, message)
+		}
+	}
+}
+
+type parallelTee struct {
+	timeout      time.Duration
+	destinations []logwrap.Impl
+
+	mutex *sync.Mutex
+	stuck []int
+}
+
+// delivery is a single message being delivered to a destination.
+type delivery struct {
+	index    int
+	result   chan interface{}
+	done     bool
+	timedOut bool
+}
+
+// deliverConcurrently starts delivery to all destinations, then waits for them to return or the timeout to pass.
+func (p *parallelTee) deliverConcurrently(ctx context.Context, message logwrap.Message) {
+	deliveries := make([]*delivery, len(p.destinations))
+
+	for i := range p.destinations {
+		deliveries[i] = p.start(ctx, i, message)
+	}
+
+	timer, stop := p.timer()
+	defer stop()
+
+	for i, d := range deliveries {
+		if d == nil {
+			continue
+		}
+
+		select {
+		case <-d.result:
+		case <-timer:
+			for _, remaining := range deliveries[i:] {
+				p.reportRunning(ctx, remaining)
+			}
+			return
+		}
+	}
+}
+
+// deliverInTurn delivers the message to each destination in turn, panicking afterwards if the message is at Panic level
+// and a destination panicked.
+func (p *parallelTee) deliverInTurn(ctx context.Context, message logwrap.Message) {
+	panicked := false
+
+	for i := range p.destinations {
+		d := p.start(ctx, i, message)
+		if d == nil {
+			continue
+		}
+
+		timer, stop := p.timer()
+
+		select {
+		case r := <-d.result:
+			panicked = panicked || r != nil
+		case <-timer:
+			p.reportRunning(ctx, d)
+		}
+
+		stop()
+	}
+
+	if panicked && message.Level == logwrap.Panic {
+		panic(message.Message)
+	}
+}
+
+// start delivers a clone of the message to the destination on a new go routine, returning the delivery whose result
+// receives any recovered panic once it returns. If the destination has a delivery which timed out and is still running,
+// the message is dropped and nil returned.
+func (p *parallelTee) start(ctx context.Context, i int, message logwrap.Message) *delivery {
+	p.mutex.Lock()
+	stuck := p.stuck[i] > 0
+	p.mutex.Unlock()
+
+	if stuck {
+		logwrap.ReportError(ctx, fmt.Errorf("tee: destination %d has not completed a timed out message, message dropped", i))
+		return nil
+	}
+
+	d := &delivery{index: i, result: make(chan interface{}, 1)}
+
+	go func(impl logwrap.Impl, message logwrap.Message) {
+		var recovered interface{}
+
+		defer func() {
+			p.mutex.Lock()
+			d.done = true
+			if d.timedOut {
+				p.stuck[i]--
+			}
+			p.mutex.Unlock()
+
+			d.result <- recovered
+		}()
+
+		defer func() {
+			if r := recover(); r != nil {
+				recovered = r
+
+				if message.Level != logwrap.Panic {
+					logwrap.ReportError(ctx, fmt.Errorf("tee: destination %d panicked: %v", i, r))
+				}
+			}
+		}()
+
+		impl(ctx, message)
+	}(p.destinations[i], message.Clone())
+
+	return d
+}
+
+// timer returns a channel which receives once the timeout passes, or never if there is no timeout.
+func (p *parallelTee) timer() (<-chan time.Time, func()) {
+	if p.timeout <= 0 {
+		return nil, func() {}
+	}
+
+	t := time.NewTimer(p.timeout)
+	return t.C, func() { t.Stop() }
+}
+
+// reportRunning reports the delivery if it has been started and not yet returned, marking its destination as stuck
+// until it does.
+func (p *parallelTee) reportRunning(ctx context.Context, d *delivery) {
+	if d == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	running := !d.done
+	if running {
+		d.timedOut = true
+		p.stuck[d.index]++
+	}
+	p.mutex.Unlock()
+
+	if running {
+		logwrap.ReportError(ctx, fmt.Errorf("tee: destination %d did not complete within %s", d.index, p.timeout))
+	}
+}
+
+// ParallelTeeSink is a Sink variant of ParallelTee, flushing or closing it flushes or closes each destination in turn.
+func ParallelTeeSink(timeout time.Duration, destinations ...logwrap.Sink) logwrap.Sink {
+	impls := make([]logwrap.Impl, 0, len(destinations))
+	lifecycles := make([]logwrap.Lifecycle, 0, len(destinations))
+
+	for _, destination := range destinations {
+		impls = append(impls, destination.Impl())
+		lifecycles = append(lifecycles, destination)
+	}
+
+	return logwrap.WithLifecycle(ParallelTee(timeout, impls...), func(ctx context.Context) error {
+		return logwrap.FlushAll(ctx, lifecycles...)
+	}, func(ctx context.Context) error {
+		return logwrap.CloseAll(ctx, lifecycles...)
+	})
+}
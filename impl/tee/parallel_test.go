@@ -0,0 +1,178 @@
+package tee
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/capture"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallelTee(t *testing.T) {
+	t.Run("distributes the message to each destination with its own copy of the data", func(t *testing.T) {
+		one := capture.NewCapture()
+		two := capture.NewCapture()
+
+		mutating := func(ctx context.Context, message logwrap.Message) {
+			message.Data["key"] = "mutated"
+		}
+
+		tee := ParallelTee(0, mutating, one.Impl(), two.Impl())
+
+		expectedData := map[string]interface{}{"key": "value"}
+		tee(context.Background(), logwrap.Message{Message: "message", Data: expectedData})
+
+		assert.Equal(t, "value", one.Messages()[0].Data["key"])
+		assert.Equal(t, "value", two.Messages()[0].Data["key"])
+		assert.Equal(t, "value", expectedData["key"])
+	})
+
+	t.Run("recovers and reports panicking destinations without affecting others", func(t *testing.T) {
+		one := capture.NewCapture()
+
+		var reported []error
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported = append(reported, err)
+		})
+
+		panicking := func(ctx context.Context, message logwrap.Message) {
+			panic("broken")
+		}
+
+		tee := ParallelTee(0, panicking, one.Impl())
+
+		assert.NotPanics(t, func() {
+			tee(ctx, logwrap.Message{Level: logwrap.Info, Message: "message"})
+		})
+
+		assert.Len(t, one.Messages(), 1)
+		assert.Len(t, reported, 1)
+		assert.Contains(t, reported[0].Error(), "broken")
+	})
+
+	t.Run("panics once all destinations return if a destination panics on a panic level message", func(t *testing.T) {
+		one := capture.NewCapture()
+
+		panicking := func(ctx context.Context, message logwrap.Message) {
+			panic(message.Message)
+		}
+
+		tee := ParallelTee(0, panicking, one.Impl())
+
+		var reported []error
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported = append(reported, err)
+		})
+
+		assert.Panics(t, func() {
+			tee(ctx, logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.Len(t, one.Messages(), 1)
+		assert.Empty(t, reported)
+	})
+
+	t.Run("delivers fatal messages to one destination at a time", func(t *testing.T) {
+		one := capture.NewCapture()
+		oneImpl := one.Impl()
+
+		slow := func(ctx context.Context, message logwrap.Message) {
+			time.Sleep(10 * time.Millisecond)
+			oneImpl(ctx, message)
+		}
+
+		delivered := -1
+		exiting := func(ctx context.Context, message logwrap.Message) {
+			delivered = len(one.Messages())
+		}
+
+		tee := ParallelTee(0, slow, exiting)
+		tee(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.Equal(t, 1, delivered)
+	})
+
+	t.Run("returns once the timeout passes, reporting slow destinations", func(t *testing.T) {
+		one := capture.NewCapture()
+
+		reported := make(chan error, 1)
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported <- err
+		})
+
+		gate := make(chan struct{})
+		defer close(gate)
+
+		slow := func(ctx context.Context, message logwrap.Message) {
+			<-gate
+		}
+
+		tee := ParallelTee(10*time.Millisecond, slow, one.Impl())
+		tee(ctx, logwrap.Message{Message: "message"})
+
+		assert.Len(t, one.Messages(), 1)
+		assert.Contains(t, (<-reported).Error(), "destination 0 did not complete")
+	})
+
+	t.Run("drops messages for a destination still running after a timeout", func(t *testing.T) {
+		one := capture.NewCapture()
+
+		reported := make(chan error, 3)
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reported <- err
+		})
+
+		gate := make(chan struct{})
+		calls := make(chan struct{}, 2)
+
+		slow := func(ctx context.Context, message logwrap.Message) {
+			calls <- struct{}{}
+			<-gate
+		}
+
+		tee := ParallelTee(10*time.Millisecond, slow, one.Impl())
+		tee(ctx, logwrap.Message{Message: "one"})
+		tee(ctx, logwrap.Message{Message: "two"})
+
+		close(gate)
+
+		assert.Len(t, one.Messages(), 2)
+		assert.Len(t, calls, 1)
+		assert.Contains(t, (<-reported).Error(), "destination 0 did not complete")
+		assert.Contains(t, (<-reported).Error(), "destination 0 has not completed a timed out message")
+	})
+	t.Run("delivers every message from concurrent callers when there is no timeout", func(t *testing.T) {
+		one := capture.NewCapture()
+		oneImpl := one.Impl()
+
+		var reported []error
+		reportedMutex := &sync.Mutex{}
+		ctx := logwrap.ContextWithErrorHandler(context.Background(), func(err error) {
+			reportedMutex.Lock()
+			reported = append(reported, err)
+			reportedMutex.Unlock()
+		})
+
+		slow := func(ctx context.Context, message logwrap.Message) {
+			time.Sleep(time.Millisecond)
+			oneImpl(ctx, message)
+		}
+
+		tee := ParallelTee(0, slow)
+
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tee(ctx, logwrap.Message{Level: logwrap.Info, Message: "message"})
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, one.Messages(), 100)
+		assert.Empty(t, reported)
+	})
+}
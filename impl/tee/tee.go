@@ -6,11 +6,29 @@ import (
 )
 
 // Tee is an implementation that distributes log messages to multiple different implementations. Calls are made
-// sequentially.
+// sequentially, each destination receives its own clone of the message so that modifications made by one destination
+// never affect another.
 func Tee(destinations ...logwrap.Impl) logwrap.Impl {
 	return func(ctx context.Context, message logwrap.Message) {
 		for _, impl := range destinations {
-			impl(ctx, message)
+			impl(ctx, message.Clone())
 		}
 	}
 }
+
+// TeeSink is a Sink variant of Tee, flushing or closing it flushes or closes each destination in turn.
+func TeeSink(destinations ...logwrap.Sink) logwrap.Sink {
+	impls := make([]logwrap.Impl, 0, len(destinations))
+	lifecycles := make([]logwrap.Lifecycle, 0, len(destinations))
+
+	for _, destination := range destinations {
+		impls = append(impls, destination.Impl())
+		lifecycles = append(lifecycles, destination)
+	}
+
+	return logwrap.WithLifecycle(Tee(impls...), func(ctx context.Context) error {
+		return logwrap.FlushAll(ctx, lifecycles...)
+	}, func(ctx context.Context) error {
+		return logwrap.CloseAll(ctx, lifecycles...)
+	})
+}
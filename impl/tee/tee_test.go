@@ -32,4 +32,55 @@ func TestTee(t *testing.T) {
 		assert.Equal(t, expectedMessage, mockImplOne.Calls[0].Arguments.Get(1).(logwrap.Message))
 		assert.Equal(t, expectedMessage, mockImplTwo.Calls[0].Arguments.Get(1).(logwrap.Message))
 	})
+
+	t.Run("tee gives each implementation its own clone so modifications do not leak sideways", func(t *testing.T) {
+		mockImplTwo := MockImpl{}
+		mockImplTwo.On("Impl", mock.Anything, mock.Anything).Once()
+
+		mutating := func(ctx context.Context, message logwrap.Message) {
+			message.Data["key"] = "changed"
+		}
+
+		tee := Tee(mutating, mockImplTwo.Impl)
+
+		upstreamData := map[string]interface{}{"key": "value"}
+		tee(context.Background(), logwrap.Message{Message: "message", Data: upstreamData})
+
+		assert.Equal(t, "value", mockImplTwo.Calls[0].Arguments.Get(1).(logwrap.Message).Data["key"])
+		assert.Equal(t, "value", upstreamData["key"])
+	})
+}
+
+func TestTeeSink(t *testing.T) {
+	t.Run("tee sink distributes messages and propagates flush and close to each destination", func(t *testing.T) {
+		mockImplOne := MockImpl{}
+		mockImplOne.On("Impl", mock.Anything, mock.Anything).Once()
+
+		mockImplTwo := MockImpl{}
+		mockImplTwo.On("Impl", mock.Anything, mock.Anything).Once()
+
+		flushed := 0
+		closed := 0
+
+		lifecycle := func(impl logwrap.Impl) logwrap.Sink {
+			return logwrap.WithLifecycle(impl, func(context.Context) error {
+				flushed++
+				return nil
+			}, func(context.Context) error {
+				closed++
+				return nil
+			})
+		}
+
+		sink := TeeSink(lifecycle(mockImplOne.Impl), lifecycle(mockImplTwo.Impl))
+		sink.Impl()(context.Background(), logwrap.Message{Message: "message"})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.NoError(t, sink.Close(context.Background()))
+
+		assert.True(t, mockImplOne.AssertExpectations(t))
+		assert.True(t, mockImplTwo.AssertExpectations(t))
+		assert.Equal(t, 2, flushed)
+		assert.Equal(t, 2, closed)
+	})
 }
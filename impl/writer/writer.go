@@ -0,0 +1,110 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"io"
+	"os"
+	"sync"
+)
+
+// exit is called on receipt of a Fatal message, it is a variable to permit testing.
+var exit = os.Exit
+
+// Writer is an implementation which renders each message with the formatter provided and writes it to an io.Writer.
+// Each message is written with a single call to Write, and calls are serialised, so the implementation is safe for
+// concurrent use. Messages which the formatter fails to render are not written, formatting and write errors are reported
+// with logwrap.ReportError.
+//
+// Panic and Fatal semantics are obeyed once the message has been written.
+func Writer(w io.Writer, formatter format.Formatter) logwrap.Impl {
+	return WriterSink(w, formatter).Impl()
+}
+
+// WriterSink is a Sink variant of Writer. Flushing it calls the writers Flush method if present (such as a
+// bufio.Writer), otherwise its Sync method if present (such as an os.File). Closing it flushes and then calls the
+// writers Close method if present. The standard output and error streams are never synced or closed.
+func WriterSink(w io.Writer, formatter format.Formatter) logwrap.Sink {
+	return &sink{
+		writer:    w,
+		formatter: formatter,
+		mutex:     &sync.Mutex{},
+	}
+}
+
+type sink struct {
+	writer    io.Writer
+	formatter format.Formatter
+	mutex     *sync.Mutex
+}
+
+func (s *sink) Impl() logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if data, err := s.formatter.Format(message); err != nil {
+			logwrap.ReportError(ctx, fmt.Errorf("writer: failed to format message: %w", err))
+		} else {
+			s.mutex.Lock()
+			_, err := s.writer.Write(data)
+			s.mutex.Unlock()
+
+			if err != nil {
+				logwrap.ReportError(ctx, fmt.Errorf("writer: failed to write message: %w", err))
+			}
+		}
+
+		switch message.Level {
+		case logwrap.Panic:
+			panic(message.Message)
+		case logwrap.Fatal:
+			_ = s.Flush(context.Background())
+			exit(-1)
+		}
+	}
+}
+
+func (s *sink) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if isStandardStream(s.writer) {
+		return nil
+	}
+
+	switch w := s.writer.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	default:
+		return nil
+	}
+}
+
+func (s *sink) Close(ctx context.Context) error {
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if isStandardStream(s.writer) {
+		return nil
+	}
+
+	if closer, ok := s.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func isStandardStream(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
@@ -0,0 +1,132 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	messageFormatter := format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+		return []byte(message.Message + "\n"), nil
+	})
+
+	t.Run("writes the output of the formatter to the writer", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Writer(&outputBuffer, messageFormatter)
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "two"})
+
+		assert.Equal(t, "one\ntwo\n", outputBuffer.String())
+	})
+
+	t.Run("messages which fail to format are not written", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Writer(&outputBuffer, format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+			return []byte("partial"), errors.New("failure")
+		}))
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+
+		assert.Empty(t, outputBuffer.String())
+	})
+
+	t.Run("panic level messages are written and then panic", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Writer(&outputBuffer, messageFormatter)
+
+		assert.Panics(t, func() {
+			impl(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.Equal(t, "message\n", outputBuffer.String())
+	})
+
+	t.Run("fatal level messages are written and then exit", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+		impl := Writer(&outputBuffer, messageFormatter)
+
+		exitCode := 0
+		exit = func(code int) { exitCode = code }
+		defer func() { exit = os.Exit }()
+
+		impl(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.Equal(t, -1, exitCode)
+		assert.Equal(t, "message\n", outputBuffer.String())
+	})
+}
+
+type flushCloseBuffer struct {
+	bytes.Buffer
+	flushed bool
+	closed  bool
+}
+
+func (b *flushCloseBuffer) Flush() error {
+	b.flushed = true
+	return nil
+}
+
+func (b *flushCloseBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestWriterSink(t *testing.T) {
+	messageFormatter := format.FormatterFunc(func(message logwrap.Message) ([]byte, error) {
+		return []byte(message.Message + "\n"), nil
+	})
+
+	t.Run("flush and close are propagated to the writer", func(t *testing.T) {
+		outputBuffer := &flushCloseBuffer{}
+		sink := WriterSink(outputBuffer, messageFormatter)
+
+		sink.Impl()(context.Background(), logwrap.Message{Level: logwrap.Info, Message: "one"})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.True(t, outputBuffer.flushed)
+		assert.False(t, outputBuffer.closed)
+
+		assert.NoError(t, sink.Close(context.Background()))
+		assert.True(t, outputBuffer.closed)
+
+		assert.Equal(t, "one\n", outputBuffer.String())
+	})
+
+	t.Run("flush returns the error of a done context without flushing", func(t *testing.T) {
+		outputBuffer := &flushCloseBuffer{}
+		sink := WriterSink(outputBuffer, messageFormatter)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Equal(t, context.Canceled, sink.Flush(ctx))
+		assert.False(t, outputBuffer.flushed)
+	})
+
+	t.Run("the standard streams are never closed", func(t *testing.T) {
+		sink := WriterSink(os.Stdout, messageFormatter)
+
+		assert.NoError(t, sink.Close(context.Background()))
+		assert.True(t, isStandardStream(os.Stdout))
+	})
+
+	t.Run("fatal level messages flush the writer before exiting", func(t *testing.T) {
+		outputBuffer := &flushCloseBuffer{}
+		sink := WriterSink(outputBuffer, messageFormatter)
+
+		exit = func(code int) {}
+		defer func() { exit = os.Exit }()
+
+		sink.Impl()(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.True(t, outputBuffer.flushed)
+	})
+}
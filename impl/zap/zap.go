@@ -0,0 +1,86 @@
+package zap
+
+import (
+	"context"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	realZap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// Wrap implements a zap wrapper, allowing the output from logwrap to be sent to zap.
+//
+// The message timestamp is preserved, the Source is used as the logger name and data values are converted into typed
+// zap fields. zap has no trace level, as such Trace messages are logged at Debug. Panic and Fatal semantics are
+// delegated to zap.
+func Wrap(dest *realZap.Logger) logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		logger := dest
+		if message.Source != "" {
+			logger = logger.Named(message.Source)
+		}
+
+		checked := logger.Check(mapLogLevels(message.Level), message.Message)
+		if checked == nil {
+			return
+		}
+
+		checked.Time = message.Timestamp
+
+		fields := make([]zapcore.Field, 0, len(message.Data))
+		for _, key := range format.SortedKeys(message.Data) {
+			fields = append(fields, mapField(key, message.Data[key]))
+		}
+
+		checked.Write(fields...)
+	}
+}
+
+func mapField(key string, value interface{}) zapcore.Field {
+	switch v := value.(type) {
+	case string:
+		return realZap.String(key, v)
+	case bool:
+		return realZap.Bool(key, v)
+	case int:
+		return realZap.Int(key, v)
+	case int64:
+		return realZap.Int64(key, v)
+	case uint64:
+		return realZap.Uint64(key, v)
+	case float64:
+		return realZap.Float64(key, v)
+	case []byte:
+		return realZap.ByteString(key, v)
+	case time.Time:
+		return realZap.Time(key, v)
+	case time.Duration:
+		return realZap.Duration(key, v)
+	case logwrap.SourceLocation:
+		return realZap.String(key, format.String(v, time.RFC3339Nano))
+	case error:
+		return realZap.NamedError(key, v)
+	default:
+		return realZap.Any(key, v)
+	}
+}
+
+func mapLogLevels(level logwrap.LogLevel) zapcore.Level {
+	switch level {
+	case logwrap.Panic:
+		return zapcore.PanicLevel
+	case logwrap.Fatal:
+		return zapcore.FatalLevel
+	case logwrap.Error:
+		return zapcore.ErrorLevel
+	case logwrap.Warn:
+		return zapcore.WarnLevel
+	case logwrap.Info:
+		return zapcore.InfoLevel
+	case logwrap.Debug, logwrap.Trace:
+		return zapcore.DebugLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
@@ -0,0 +1,85 @@
+package zap
+
+import (
+	"context"
+	"errors"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	realZap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_mapLogLevels(t *testing.T) {
+	t.Run("maps logwrap to zap log levels", func(t *testing.T) {
+		assert.Equal(t, zapcore.PanicLevel, mapLogLevels(logwrap.Panic))
+		assert.Equal(t, zapcore.FatalLevel, mapLogLevels(logwrap.Fatal))
+		assert.Equal(t, zapcore.ErrorLevel, mapLogLevels(logwrap.Error))
+		assert.Equal(t, zapcore.WarnLevel, mapLogLevels(logwrap.Warn))
+		assert.Equal(t, zapcore.InfoLevel, mapLogLevels(logwrap.Info))
+		assert.Equal(t, zapcore.DebugLevel, mapLogLevels(logwrap.Debug))
+		assert.Equal(t, zapcore.DebugLevel, mapLogLevels(logwrap.Trace))
+
+		// Unknown logwrap level.
+		assert.Equal(t, zapcore.InfoLevel, mapLogLevels(logwrap.LogLevel(math.MaxUint64)))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("wrap correctly sends log with message, level, time, name and typed fields", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+
+		expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+		expectedErr := errors.New("failure")
+
+		Wrap(realZap.New(core))(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Timestamp: expectedTime,
+			Source:    "source",
+			Data: map[string]interface{}{
+				"string":   "value",
+				"int":      5,
+				"duration": time.Second,
+				"err":      expectedErr,
+				"location": logwrap.SourceLocation{File: "/src/main.go", Line: 42},
+			},
+		})
+
+		assert.Equal(t, 1, logs.Len())
+		entry := logs.All()[0]
+
+		assert.Equal(t, zapcore.WarnLevel, entry.Level)
+		assert.Equal(t, "message", entry.Message)
+		assert.Equal(t, expectedTime, entry.Time)
+		assert.Equal(t, "source", entry.LoggerName)
+
+		fields := entry.ContextMap()
+		assert.Equal(t, "value", fields["string"])
+		assert.Equal(t, int64(5), fields["int"])
+		assert.Equal(t, time.Second, fields["duration"])
+		assert.Equal(t, "failure", fields["err"])
+		assert.Equal(t, "/src/main.go:42", fields["location"])
+	})
+
+	t.Run("wrap does not log messages below the zap level", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+
+		Wrap(realZap.New(core))(context.Background(), logwrap.Message{Level: logwrap.Trace, Message: "message"})
+
+		assert.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("wrap delegates panic semantics to zap", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+
+		assert.Panics(t, func() {
+			Wrap(realZap.New(core))(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.Equal(t, 1, logs.Len())
+	})
+}
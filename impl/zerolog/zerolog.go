@@ -0,0 +1,106 @@
+package zerolog
+
+import (
+	"context"
+	"fmt"
+	realZerolog "github.com/rs/zerolog"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/shimmeringbee/logwrap/impl/format"
+	"os"
+	"reflect"
+	"time"
+)
+
+// LoggerFieldName is the field name the message Source is written under, as zerolog has no concept of a logger name.
+var LoggerFieldName = "logger"
+
+// exit is called on receipt of a Fatal message, it is a variable to permit testing.
+var exit = os.Exit
+
+// Wrap implements a zerolog wrapper, allowing the output from logwrap to be sent to zerolog.
+//
+// The message timestamp is written under zerolog.TimestampFieldName, as such the destination logger should not also be
+// configured to add a timestamp. The Source is written under LoggerFieldName and data values are written as typed
+// fields in key order, nil pointers are written as null. As zerolog does not panic or exit when logging with an
+// explicit level, Wrap does so once the message has been logged for Panic and Fatal messages.
+func Wrap(dest realZerolog.Logger) logwrap.Impl {
+	return func(ctx context.Context, message logwrap.Message) {
+		if event := dest.WithLevel(mapLogLevels(message.Level)); event != nil {
+			event.Time(realZerolog.TimestampFieldName, message.Timestamp)
+
+			if message.Source != "" {
+				event.Str(LoggerFieldName, message.Source)
+			}
+
+			for _, key := range format.SortedKeys(message.Data) {
+				addField(event, key, message.Data[key])
+			}
+
+			event.Msg(message.Message)
+		}
+
+		switch message.Level {
+		case logwrap.Panic:
+			panic(message.Message)
+		case logwrap.Fatal:
+			exit(-1)
+		}
+	}
+}
+
+func addField(event *realZerolog.Event, key string, value interface{}) {
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		event.Interface(key, nil)
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		event.Str(key, v)
+	case bool:
+		event.Bool(key, v)
+	case int:
+		event.Int(key, v)
+	case int64:
+		event.Int64(key, v)
+	case uint64:
+		event.Uint64(key, v)
+	case float64:
+		event.Float64(key, v)
+	case []byte:
+		event.Bytes(key, v)
+	case time.Time:
+		event.Time(key, v)
+	case time.Duration:
+		event.Dur(key, v)
+	case logwrap.SourceLocation:
+		event.Str(key, format.String(v, time.RFC3339Nano))
+	case error:
+		event.AnErr(key, v)
+	case fmt.Stringer:
+		event.Str(key, v.String())
+	default:
+		event.Interface(key, v)
+	}
+}
+
+func mapLogLevels(level logwrap.LogLevel) realZerolog.Level {
+	switch level {
+	case logwrap.Panic:
+		return realZerolog.PanicLevel
+	case logwrap.Fatal:
+		return realZerolog.FatalLevel
+	case logwrap.Error:
+		return realZerolog.ErrorLevel
+	case logwrap.Warn:
+		return realZerolog.WarnLevel
+	case logwrap.Info:
+		return realZerolog.InfoLevel
+	case logwrap.Debug:
+		return realZerolog.DebugLevel
+	case logwrap.Trace:
+		return realZerolog.TraceLevel
+	default:
+		return realZerolog.InfoLevel
+	}
+}
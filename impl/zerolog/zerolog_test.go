@@ -0,0 +1,128 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	realZerolog "github.com/rs/zerolog"
+	"github.com/shimmeringbee/logwrap"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+type pointerError struct{}
+
+func (e *pointerError) Error() string {
+	return "failure"
+}
+
+type pointerStringer struct{}
+
+func (s *pointerStringer) String() string {
+	return "value"
+}
+
+func Test_mapLogLevels(t *testing.T) {
+	t.Run("maps logwrap to zerolog log levels", func(t *testing.T) {
+		assert.Equal(t, realZerolog.PanicLevel, mapLogLevels(logwrap.Panic))
+		assert.Equal(t, realZerolog.FatalLevel, mapLogLevels(logwrap.Fatal))
+		assert.Equal(t, realZerolog.ErrorLevel, mapLogLevels(logwrap.Error))
+		assert.Equal(t, realZerolog.WarnLevel, mapLogLevels(logwrap.Warn))
+		assert.Equal(t, realZerolog.InfoLevel, mapLogLevels(logwrap.Info))
+		assert.Equal(t, realZerolog.DebugLevel, mapLogLevels(logwrap.Debug))
+		assert.Equal(t, realZerolog.TraceLevel, mapLogLevels(logwrap.Trace))
+
+		// Unknown logwrap level.
+		assert.Equal(t, realZerolog.InfoLevel, mapLogLevels(logwrap.LogLevel(math.MaxUint64)))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("wrap correctly sends log with message, level, time, logger and typed fields", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+
+		expectedTime := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+
+		Wrap(realZerolog.New(&outputBuffer))(context.Background(), logwrap.Message{
+			Level:     logwrap.Warn,
+			Message:   "message",
+			Timestamp: expectedTime,
+			Source:    "source",
+			Data: map[string]interface{}{
+				"string":   "value",
+				"int":      5,
+				"err":      errors.New("failure"),
+				"location": logwrap.SourceLocation{File: "/src/main.go", Line: 42},
+			},
+		})
+
+		decoded := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &decoded))
+
+		assert.Equal(t, "warn", decoded[realZerolog.LevelFieldName])
+		assert.Equal(t, "message", decoded[realZerolog.MessageFieldName])
+		assert.Equal(t, "2020-06-01T12:30:00Z", decoded[realZerolog.TimestampFieldName])
+		assert.Equal(t, "source", decoded[LoggerFieldName])
+		assert.Equal(t, "value", decoded["string"])
+		assert.Equal(t, float64(5), decoded["int"])
+		assert.Equal(t, "failure", decoded["err"])
+		assert.Equal(t, "/src/main.go:42", decoded["location"])
+	})
+
+	t.Run("typed nil errors and stringers are written as null rather than panicking", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+
+		Wrap(realZerolog.New(&outputBuffer))(context.Background(), logwrap.Message{
+			Level:   logwrap.Info,
+			Message: "message",
+			Data: map[string]interface{}{
+				"e": (*pointerError)(nil),
+				"s": (*pointerStringer)(nil),
+			},
+		})
+
+		decoded := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &decoded))
+
+		assert.Equal(t, "message", decoded[realZerolog.MessageFieldName])
+		assert.Contains(t, decoded, "e")
+		assert.Nil(t, decoded["e"])
+		assert.Contains(t, decoded, "s")
+		assert.Nil(t, decoded["s"])
+	})
+
+	t.Run("wrap does not log messages below the zerolog level", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+
+		Wrap(realZerolog.New(&outputBuffer).Level(realZerolog.InfoLevel))(context.Background(), logwrap.Message{Level: logwrap.Debug, Message: "message"})
+
+		assert.Empty(t, outputBuffer.String())
+	})
+
+	t.Run("wrap panics after logging panic level messages", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+
+		assert.Panics(t, func() {
+			Wrap(realZerolog.New(&outputBuffer))(context.Background(), logwrap.Message{Level: logwrap.Panic, Message: "message"})
+		})
+
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+
+	t.Run("wrap exits after logging fatal level messages", func(t *testing.T) {
+		var outputBuffer bytes.Buffer
+
+		exitCode := 0
+		exit = func(code int) { exitCode = code }
+		defer func() { exit = os.Exit }()
+
+		Wrap(realZerolog.New(&outputBuffer))(context.Background(), logwrap.Message{Level: logwrap.Fatal, Message: "message"})
+
+		assert.Equal(t, -1, exitCode)
+		assert.NotEmpty(t, outputBuffer.String())
+	})
+}
@@ -0,0 +1,115 @@
+package logwrap
+
+import "context"
+
+// Lifecycle is implemented by implementations which buffer messages or hold resources, such as open files, which must
+// be flushed or released on shutdown. Both methods should respect the deadline of the context provided.
+type Lifecycle interface {
+	// Flush ensures any buffered messages have been delivered.
+	Flush(context.Context) error
+	// Close flushes any buffered messages and releases held resources, messages should not be logged after Close.
+	Close(context.Context) error
+}
+
+// Sink is an implementation paired with its Lifecycle. Wrapping implementations provide Sink variants which propagate
+// the Lifecycle to their destinations.
+type Sink interface {
+	Lifecycle
+	// Impl returns the implementation that can be passed to logwrap.
+	Impl() Impl
+}
+
+// WithLifecycle pairs an implementation with functions to flush and close it, either function may be nil.
+func WithLifecycle(impl Impl, flush func(context.Context) error, close func(context.Context) error) Sink {
+	return lifecycleSink{
+		impl:  impl,
+		flush: flush,
+		close: close,
+	}
+}
+
+// NoLifecycle wraps an implementation which has no lifecycle as a Sink.
+func NoLifecycle(impl Impl) Sink {
+	return WithLifecycle(impl, nil, nil)
+}
+
+// FlushAll flushes each Lifecycle provided in turn, returning the first error encountered once all have been flushed.
+func FlushAll(ctx context.Context, lifecycles ...Lifecycle) error {
+	var firstErr error
+
+	for _, lifecycle := range lifecycles {
+		if err := lifecycle.Flush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// CloseAll closes each Lifecycle provided in turn, returning the first error encountered once all have been closed.
+func CloseAll(ctx context.Context, lifecycles ...Lifecycle) error {
+	var firstErr error
+
+	for _, lifecycle := range lifecycles {
+		if err := lifecycle.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+type lifecycleSink struct {
+	impl  Impl
+	flush func(context.Context) error
+	close func(context.Context) error
+}
+
+func (s lifecycleSink) Impl() Impl {
+	return s.impl
+}
+
+func (s lifecycleSink) Flush(ctx context.Context) error {
+	if s.flush == nil {
+		return ctx.Err()
+	}
+
+	return s.flush(ctx)
+}
+
+func (s lifecycleSink) Close(ctx context.Context) error {
+	if s.close == nil {
+		return s.Flush(ctx)
+	}
+
+	return s.close(ctx)
+}
+
+// NewWithSink constructs a new logger, taking a Sink whose implementation will actually log and whose Lifecycle is
+// managed with the loggers Flush and Close.
+func NewWithSink(s Sink) Logger {
+	l := New(s.Impl())
+	l.lifecycle = s
+
+	return l
+}
+
+// Flush flushes the loggers Sink, ensuring that any buffered messages have been delivered. It is a no-op for loggers
+// constructed with New.
+func (l Logger) Flush(ctx context.Context) error {
+	if l.lifecycle == nil {
+		return nil
+	}
+
+	return l.lifecycle.Flush(ctx)
+}
+
+// Close flushes and closes the loggers Sink, and so the whole pipeline of implementations behind it. It should be
+// called on shutdown, messages should not be logged after Close. It is a no-op for loggers constructed with New.
+func (l Logger) Close(ctx context.Context) error {
+	if l.lifecycle == nil {
+		return nil
+	}
+
+	return l.lifecycle.Close(ctx)
+}
@@ -0,0 +1,99 @@
+package logwrap
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type MockLifecycle struct {
+	mock.Mock
+}
+
+func (m *MockLifecycle) Flush(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockLifecycle) Close(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestWithLifecycle(t *testing.T) {
+	t.Run("calls the flush and close functions provided", func(t *testing.T) {
+		flushed := false
+		closed := false
+
+		sink := WithLifecycle(func(context.Context, Message) {}, func(context.Context) error {
+			flushed = true
+			return nil
+		}, func(context.Context) error {
+			closed = true
+			return nil
+		})
+
+		assert.NoError(t, sink.Flush(context.Background()))
+		assert.NoError(t, sink.Close(context.Background()))
+
+		assert.True(t, flushed)
+		assert.True(t, closed)
+	})
+
+	t.Run("a sink without lifecycle returns the error of a done context", func(t *testing.T) {
+		sink := NoLifecycle(func(context.Context, Message) {})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.NoError(t, sink.Close(ctx))
+
+		cancel()
+		assert.Equal(t, context.Canceled, sink.Flush(ctx))
+	})
+}
+
+func TestFlushAll_CloseAll(t *testing.T) {
+	t.Run("calls every lifecycle and returns the first error", func(t *testing.T) {
+		expectedErr := errors.New("first")
+
+		first := &MockLifecycle{}
+		first.On("Flush", mock.Anything).Return(expectedErr)
+		first.On("Close", mock.Anything).Return(expectedErr)
+
+		second := &MockLifecycle{}
+		second.On("Flush", mock.Anything).Return(errors.New("second"))
+		second.On("Close", mock.Anything).Return(nil)
+
+		assert.Equal(t, expectedErr, FlushAll(context.Background(), first, second))
+		assert.Equal(t, expectedErr, CloseAll(context.Background(), first, second))
+
+		assert.True(t, first.AssertExpectations(t))
+		assert.True(t, second.AssertExpectations(t))
+	})
+}
+
+func TestLogger_FlushClose(t *testing.T) {
+	t.Run("flush and close are propagated to the loggers sink", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Once()
+
+		lifecycle := &MockLifecycle{}
+		lifecycle.On("Flush", mock.Anything).Return(nil).Once()
+		lifecycle.On("Close", mock.Anything).Return(nil).Once()
+
+		logger := NewWithSink(WithLifecycle(mockImpl.Impl, lifecycle.Flush, lifecycle.Close))
+		logger.Log(context.Background(), "message")
+
+		assert.NoError(t, logger.Flush(context.Background()))
+		assert.NoError(t, logger.Close(context.Background()))
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+		assert.True(t, lifecycle.AssertExpectations(t))
+	})
+
+	t.Run("flush and close are no-ops for loggers constructed with New", func(t *testing.T) {
+		logger := New(func(context.Context, Message) {})
+
+		assert.NoError(t, logger.Flush(context.Background()))
+		assert.NoError(t, logger.Close(context.Background()))
+	})
+}
@@ -13,7 +13,8 @@ import (
 // use of go concurrency techniques to remove blocking code from calling functions go routine.
 //
 // Should an implementation block by design (such as assured delivery of logs), this should be made explicitly clear in
-// any documentation.
+// any documentation. Implementations which buffer messages or hold resources should also provide a Sink, so that they
+// can be flushed and closed on shutdown.
 //
 // Implementations should obey the semantics of Panic and Fatal levels, panic()ing and os.Exit(-1) respectively after
 // the log has been made.
@@ -75,6 +76,7 @@ type Logger struct {
 	unique    uint64
 	segmentID *uint64
 	options   []Option
+	lifecycle Lifecycle
 }
 
 // Option is an interface for a option a Log call can take, adding or modifying data on a Message.
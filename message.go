@@ -0,0 +1,62 @@
+package logwrap
+
+import "reflect"
+
+// Clone returns a deep copy of the message. Maps, slices and arrays within Data are copied recursively, so the clone
+// may be modified without affecting the original. Pointers, structs and other values are shared.
+//
+// Implementations which modify a message, or retain it beyond the call, should operate on a clone so that their
+// changes never leak to other implementations.
+func (m Message) Clone() Message {
+	if m.Data != nil {
+		m.Data = cloneValue(reflect.ValueOf(m.Data)).Interface().(map[string]interface{})
+	}
+
+	return m
+}
+
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.New(value.Type()).Elem()
+		clone.Set(cloneValue(value.Elem()))
+		return clone
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iterator := value.MapRange()
+
+		for iterator.Next() {
+			clone.SetMapIndex(iterator.Key(), cloneValue(iterator.Value()))
+		}
+
+		return clone
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			clone.Index(i).Set(cloneValue(value.Index(i)))
+		}
+
+		return clone
+	case reflect.Array:
+		clone := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			clone.Index(i).Set(cloneValue(value.Index(i)))
+		}
+
+		return clone
+	default:
+		return value
+	}
+}
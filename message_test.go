@@ -0,0 +1,53 @@
+package logwrap
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type clonePointed struct {
+	Value string
+}
+
+func TestMessage_Clone(t *testing.T) {
+	t.Run("clones data deeply so modifications do not affect the original", func(t *testing.T) {
+		pointer := &clonePointed{Value: "shared"}
+
+		original := Message{
+			Message: "message",
+			Data: map[string]interface{}{
+				"key":     "value",
+				"map":     map[string]interface{}{"nested": "value"},
+				"list":    List{"nested": "value"},
+				"slice":   []interface{}{"value", map[string]int{"nested": 1}},
+				"bytes":   []byte("value"),
+				"array":   [1]string{"value"},
+				"pointer": pointer,
+				"nil":     nil,
+			},
+		}
+
+		clone := original.Clone()
+		assert.Equal(t, original, clone)
+
+		clone.Data["key"] = "changed"
+		clone.Data["map"].(map[string]interface{})["nested"] = "changed"
+		clone.Data["list"].(List)["nested"] = "changed"
+		clone.Data["slice"].([]interface{})[0] = "changed"
+		clone.Data["slice"].([]interface{})[1].(map[string]int)["nested"] = 2
+		clone.Data["bytes"].([]byte)[0] = 'V'
+
+		assert.Equal(t, "value", original.Data["key"])
+		assert.Equal(t, "value", original.Data["map"].(map[string]interface{})["nested"])
+		assert.Equal(t, "value", original.Data["list"].(List)["nested"])
+		assert.Equal(t, "value", original.Data["slice"].([]interface{})[0])
+		assert.Equal(t, 1, original.Data["slice"].([]interface{})[1].(map[string]int)["nested"])
+		assert.Equal(t, []byte("value"), original.Data["bytes"])
+		assert.Same(t, pointer, clone.Data["pointer"])
+	})
+
+	t.Run("clones a message without data", func(t *testing.T) {
+		original := Message{Message: "message"}
+		assert.Equal(t, original, original.Clone())
+	})
+}
@@ -0,0 +1,120 @@
+package logwrap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"sync"
+)
+
+// MaximumWriterLineLength is the longest line a Writer will buffer, longer lines are logged as multiple messages.
+const MaximumWriterLineLength = 64 * 1024
+
+// Writer returns an io.WriteCloser which logs each line written to it as a message at the level provided, with the
+// options provided. This permits libraries which only accept an io.Writer to log through logwrap.
+//
+// Writes do not need to be aligned to lines, partial lines are buffered until completed. Lines longer than
+// MaximumWriterLineLength are split into multiple messages. Carriage returns preceding a new line and empty lines are
+// discarded. Close logs any buffered partial line. The writer is safe for concurrent use.
+func (l Logger) Writer(ctx context.Context, level LogLevel, options ...Option) io.WriteCloser {
+	return &lineWriter{
+		logger:  l,
+		ctx:     ctx,
+		options: append(append([]Option{}, options...), Level(level)),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// StdLogger returns a *log.Logger which logs each line written to it as a message at the level provided, with the
+// options provided. This permits libraries which only accept a *log.Logger, such as net/http.Server's ErrorLog, to log
+// through logwrap.
+func (l Logger) StdLogger(ctx context.Context, level LogLevel, options ...Option) *log.Logger {
+	return log.New(l.Writer(ctx, level, options...), "", 0)
+}
+
+// RedirectStdLog redirects the output of the standard libraries global log package into this logger, logging each line
+// at the level provided with the options provided. The global loggers prefix and flags are cleared, as the message
+// already carries a timestamp. The function returned restores the global logger's previous output, prefix and flags.
+func (l Logger) RedirectStdLog(ctx context.Context, level LogLevel, options ...Option) func() {
+	previousOutput := log.Writer()
+	previousPrefix := log.Prefix()
+	previousFlags := log.Flags()
+
+	writer := l.Writer(ctx, level, options...)
+
+	log.SetOutput(writer)
+	log.SetPrefix("")
+	log.SetFlags(0)
+
+	return func() {
+		log.SetOutput(previousOutput)
+		log.SetPrefix(previousPrefix)
+		log.SetFlags(previousFlags)
+
+		_ = writer.Close()
+	}
+}
+
+type lineWriter struct {
+	logger  Logger
+	ctx     context.Context
+	options []Option
+	mutex   *sync.Mutex
+	buffer  []byte
+}
+
+// Write buffers the data provided, logging each complete line.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	written := len(p)
+
+	for len(p) > 0 {
+		index := bytes.IndexByte(p, '\n')
+
+		if index < 0 {
+			w.buffer = append(w.buffer, p...)
+			p = nil
+		} else {
+			w.buffer = append(w.buffer, p[:index]...)
+			p = p[index+1:]
+
+			w.logLine(w.buffer)
+			w.buffer = w.buffer[:0]
+		}
+
+		for len(w.buffer) >= MaximumWriterLineLength {
+			w.logLine(w.buffer[:MaximumWriterLineLength])
+			w.buffer = append(w.buffer[:0], w.buffer[MaximumWriterLineLength:]...)
+		}
+	}
+
+	return written, nil
+}
+
+// Close logs any partial line which remains buffered.
+func (w *lineWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.logLine(w.buffer)
+	w.buffer = nil
+
+	return nil
+}
+
+// logLine logs a line, splitting it into multiple messages if longer than MaximumWriterLineLength.
+func (w *lineWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+
+	for len(line) > MaximumWriterLineLength {
+		w.logger.Log(w.ctx, string(line[:MaximumWriterLineLength]), w.options...)
+		line = line[MaximumWriterLineLength:]
+	}
+
+	if len(line) > 0 {
+		w.logger.Log(w.ctx, string(line), w.options...)
+	}
+}
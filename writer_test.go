@@ -0,0 +1,112 @@
+package logwrap
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Writer(t *testing.T) {
+	t.Run("logs each line written as a message at the level and with the options provided", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Twice()
+
+		logger := New(mockImpl.Impl)
+		writer := logger.Writer(context.Background(), Warn, Datum("key", "value"))
+
+		n, err := writer.Write([]byte("first\r\n\nsecond\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 15, n)
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+
+		capturedMessage := mockImpl.Calls[0].Arguments.Get(1).(Message)
+		assert.Equal(t, "first", capturedMessage.Message)
+		assert.Equal(t, Warn, capturedMessage.Level)
+		assert.Equal(t, "value", capturedMessage.Data["key"])
+
+		capturedMessage = mockImpl.Calls[1].Arguments.Get(1).(Message)
+		assert.Equal(t, "second", capturedMessage.Message)
+	})
+
+	t.Run("buffers partial writes until a line is complete, and logs remaining data on close", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Twice()
+
+		logger := New(mockImpl.Impl)
+		writer := logger.Writer(context.Background(), Info)
+
+		_, _ = writer.Write([]byte("fir"))
+		_, _ = writer.Write([]byte("st\nsec"))
+		_, _ = writer.Write([]byte("ond"))
+
+		assert.Len(t, mockImpl.Calls, 1)
+		assert.Equal(t, "first", mockImpl.Calls[0].Arguments.Get(1).(Message).Message)
+
+		assert.NoError(t, writer.Close())
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+		assert.Equal(t, "second", mockImpl.Calls[1].Arguments.Get(1).(Message).Message)
+	})
+
+	t.Run("splits lines longer than the maximum line length", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Twice()
+
+		logger := New(mockImpl.Impl)
+		writer := logger.Writer(context.Background(), Info)
+
+		_, _ = writer.Write([]byte(strings.Repeat("a", MaximumWriterLineLength+10) + "\n"))
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+		assert.Len(t, mockImpl.Calls[0].Arguments.Get(1).(Message).Message, MaximumWriterLineLength)
+		assert.Len(t, mockImpl.Calls[1].Arguments.Get(1).(Message).Message, 10)
+	})
+}
+
+func TestLogger_StdLogger(t *testing.T) {
+	t.Run("returns a log.Logger which logs through the logger", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Once()
+
+		logger := New(mockImpl.Impl)
+		logger.StdLogger(context.Background(), Error).Printf("failed %d", 1)
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+
+		capturedMessage := mockImpl.Calls[0].Arguments.Get(1).(Message)
+		assert.Equal(t, "failed 1", capturedMessage.Message)
+		assert.Equal(t, Error, capturedMessage.Level)
+	})
+}
+
+func TestLogger_RedirectStdLog(t *testing.T) {
+	t.Run("redirects the global log package into the logger until restored", func(t *testing.T) {
+		mockImpl := MockImpl{}
+		mockImpl.On("Impl", mock.Anything, mock.Anything).Once()
+
+		var outputBuffer bytes.Buffer
+		log.SetOutput(&outputBuffer)
+		log.SetPrefix("prefix ")
+
+		logger := New(mockImpl.Impl)
+		restore := logger.RedirectStdLog(context.Background(), Info)
+
+		log.Print("redirected")
+		restore()
+		log.Print("restored")
+
+		assert.True(t, mockImpl.AssertExpectations(t))
+		assert.Equal(t, "redirected", mockImpl.Calls[0].Arguments.Get(1).(Message).Message)
+		assert.Contains(t, outputBuffer.String(), "prefix ")
+		assert.Contains(t, outputBuffer.String(), "restored")
+
+		log.SetOutput(os.Stderr)
+		log.SetPrefix("")
+	})
+}